@@ -0,0 +1,131 @@
+package main
+
+// Checks the certificate chain served by a host is complete, in order and trusted
+
+import (
+	"bytes"
+	"crypto/tls"
+	"crypto/x509"
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"time"
+
+	"github.com/desylva/nagios/internal/nagios"
+	"github.com/desylva/nagios/internal/netcheck"
+)
+
+func main() {
+	caFile := flag.String("ca-file", "", "PEM file of trusted root certificates (default: system roots)")
+	serverName := flag.String("servername", "", "server name sent for SNI (default: host)")
+	verbose := flag.Bool("v", false, "list each certificate of the chain")
+	timeout := nagios.DurationFlag{Duration: 10 * time.Second}
+	flag.Var(&timeout, "t", "connect timeout, as a duration or in seconds")
+	flag.Var(&timeout, "timeout", "connect timeout, as a duration or in seconds")
+	family := netcheck.AddFlags()
+	nagios.ParseFlags()
+	family.Validate()
+
+	args := flag.Args()
+	if len(args) < 1 {
+		nagios.Usage("Expects 'host' and optional 'port' as first arguments")
+	}
+	host := args[0]
+	port := "443"
+	if len(args) > 1 {
+		port = args[1]
+	}
+	address := net.JoinHostPort(host, port)
+	if *serverName == "" {
+		*serverName = host
+	}
+
+	var roots *x509.CertPool
+	if *caFile != "" {
+		pem, err := os.ReadFile(*caFile)
+		if err != nil {
+			nagios.Usage("Cannot read --ca-file: %v", err)
+		}
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(pem) {
+			nagios.Usage("No certificates found in --ca-file %v", *caFile)
+		}
+	}
+
+	start := time.Now()
+	rawConn, err := family.Dial(address, timeout.Duration)
+	var conn *tls.Conn
+	if err == nil {
+		// Verification is done below on the chain as served
+		conn = tls.Client(rawConn, &tls.Config{
+			ServerName:         *serverName,
+			InsecureSkipVerify: true,
+		})
+		conn.SetDeadline(start.Add(timeout.Duration))
+		err = conn.Handshake()
+	}
+	if err, ok := err.(net.Error); ok && err.Timeout() {
+		nagios.Result{
+			Status:  nagios.Critical,
+			Summary: fmt.Sprintf("timed out after %v connecting to %v", timeout.Duration, address),
+		}.Exit()
+	}
+	if err != nil {
+		nagios.Result{Status: nagios.Critical, Summary: err.Error()}.Exit()
+	}
+	conn.Close()
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		nagios.Result{Status: nagios.Critical, Summary: "No certificate presented by " + address}.Exit()
+	}
+
+	var details []string
+	if *verbose {
+		for i, cert := range certs {
+			details = append(details, fmt.Sprintf("%v: %v, issued by %v, expires %v",
+				i, cert.Subject.CommonName, cert.Issuer.CommonName, cert.NotAfter.UTC().Format("2006-01-02")))
+		}
+	}
+	fail := func(format string, a ...interface{}) {
+		nagios.Result{Status: nagios.Critical, Summary: fmt.Sprintf(format, a...), Details: details}.Exit()
+	}
+
+	// Each certificate must be followed by the one that issued it
+	for i := 0; i+1 < len(certs); i++ {
+		if !bytes.Equal(certs[i].RawIssuer, certs[i+1].RawSubject) || certs[i].CheckSignatureFrom(certs[i+1]) != nil {
+			fail("Chain of %v is out of order: certificate %v (%v) is not issued by certificate %v (%v)",
+				address, i, certs[i].Subject.CommonName, i+1, certs[i+1].Subject.CommonName)
+		}
+	}
+
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	chains, err := certs[0].Verify(x509.VerifyOptions{
+		Roots:         roots,
+		Intermediates: intermediates,
+	})
+	if err != nil {
+		last := certs[len(certs)-1]
+		if _, ok := err.(x509.UnknownAuthorityError); ok {
+			if bytes.Equal(last.RawIssuer, last.RawSubject) {
+				fail("Chain of %v is untrusted: root %v is not a trusted authority", address, last.Subject.CommonName)
+			}
+			fail("Chain of %v is incomplete or untrusted: issuer %v of %v was not served and is not a trusted root",
+				address, last.Issuer.CommonName, last.Subject.CommonName)
+		}
+		fail("Chain of %v does not verify: %v", address, err)
+	}
+
+	chain := chains[0]
+	root := chain[len(chain)-1]
+	nagios.Result{
+		Status: nagios.OK,
+		Summary: fmt.Sprintf("Chain of %v has %v served certificates, depth %v to trusted root %v",
+			address, len(certs), len(chain), root.Subject.CommonName),
+		Details: details,
+	}.Exit()
+}
@@ -0,0 +1,118 @@
+package main
+
+// Checks the certificates in PEM files on disk are not close to expiry
+
+import (
+	"crypto/x509"
+	"encoding/pem"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/desylva/nagios/internal/nagios"
+)
+
+func main() {
+	warnDays := flag.Int("w", 30, "warning when a certificate expires in less than this many days")
+	critDays := flag.Int("c", 14, "critical when a certificate expires in less than this many days")
+	nagios.ParseFlags()
+
+	patterns := flag.Args()
+	if len(patterns) < 1 {
+		nagios.Usage("Expects one or more PEM files or globs like /etc/ssl/*.pem as arguments")
+	}
+
+	var files []string
+	for _, pattern := range patterns {
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			nagios.Usage("Invalid glob %q: %v", pattern, err)
+		}
+		if matches == nil {
+			// Not a glob, or a glob matching nothing: report the path itself
+			matches = []string{pattern}
+		}
+		files = append(files, matches...)
+	}
+
+	status := nagios.OK
+	var details []string
+	var soonest *x509.Certificate
+	var soonestFile string
+	count := 0
+	for _, file := range files {
+		certs, err := readCertificates(file)
+		if err != nil {
+			status = nagios.Worst(status, nagios.Unknown)
+			details = append(details, err.Error())
+			continue
+		}
+		count += len(certs)
+		for _, cert := range certs {
+			if soonest == nil || cert.NotAfter.Before(soonest.NotAfter) {
+				soonest, soonestFile = cert, file
+			}
+		}
+	}
+
+	if soonest == nil {
+		nagios.Result{Status: nagios.Unknown, Summary: "No certificates found", Details: details}.Exit()
+	}
+
+	days := int(time.Until(soonest.NotAfter).Hours() / 24)
+	switch {
+	case days < *critDays || time.Now().After(soonest.NotAfter):
+		status = nagios.Worst(status, nagios.Critical)
+	case days < *warnDays:
+		status = nagios.Worst(status, nagios.Warning)
+	}
+
+	expiry := fmt.Sprintf("expires in %v days", days)
+	if time.Now().After(soonest.NotAfter) {
+		expiry = "expired"
+	}
+	nagios.Result{
+		Status: status,
+		Summary: fmt.Sprintf("Certificate %v in %v %v (%v), soonest of %v certificates in %v files",
+			soonest.Subject, soonestFile, expiry, soonest.NotAfter.UTC().Format("2006-01-02 15:04:05 MST"), count, len(files)),
+		Perfdata: []nagios.Perfdata{{
+			Label: "days",
+			Value: float64(days),
+			Warn:  strconv.Itoa(*warnDays),
+			Crit:  strconv.Itoa(*critDays),
+		}},
+		Details: details,
+	}.Exit()
+}
+
+// readCertificates parses every certificate of a PEM file or bundle,
+// failing when there is none
+func readCertificates(file string) ([]*x509.Certificate, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var certs []*x509.Certificate
+	for {
+		var block *pem.Block
+		block, data = pem.Decode(data)
+		if block == nil {
+			break
+		}
+		if block.Type != "CERTIFICATE" {
+			continue
+		}
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("%v: %v", file, err)
+		}
+		certs = append(certs, cert)
+	}
+	if len(certs) == 0 {
+		return nil, fmt.Errorf("%v: no parseable certificates", file)
+	}
+	return certs, nil
+}
@@ -0,0 +1,165 @@
+package main
+
+// Checks the free space of one or more mounted filesystems
+
+import (
+	"flag"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/desylva/nagios/internal/nagios"
+	"github.com/desylva/nagios/internal/sysinfo"
+)
+
+// freeThreshold is a minimum of free space, in percent or in bytes
+type freeThreshold struct {
+	spec    string
+	percent bool
+	value   float64
+}
+
+func (t *freeThreshold) String() string {
+	return t.spec
+}
+
+// Set parses a free space like "20%", "500M" or "2G"
+func (t *freeThreshold) Set(s string) error {
+	t.spec = s
+	t.percent = strings.HasSuffix(s, "%")
+	var v float64
+	var err error
+	if t.percent {
+		v, err = strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	} else {
+		v, err = nagios.ParseSize(s)
+	}
+	if err != nil || v < 0 {
+		return fmt.Errorf("invalid free space %q, expects a percentage like 20%% or a size like 500M", s)
+	}
+	t.value = v
+	return nil
+}
+
+// set reports whether a threshold was given
+func (t *freeThreshold) set() bool {
+	return t.spec != ""
+}
+
+// minFree returns the threshold in free bytes for a filesystem of total bytes
+func (t *freeThreshold) minFree(total float64) float64 {
+	if t.percent {
+		return total * t.value / 100
+	}
+	return t.value
+}
+
+const mb = 1 << 20
+
+func main() {
+	var warn, crit freeThreshold
+	flag.Var(&warn, "w", "warning when free space is below this percentage (20%) or size (500M)")
+	flag.Var(&crit, "c", "critical when free space is below this percentage (10%) or size (100M)")
+	anyPath := flag.Bool("any-path", false, "accept paths that are not mount points, checking the filesystem holding them as df does")
+	nagios.ParseFlags()
+
+	paths := flag.Args()
+	if len(paths) < 1 {
+		nagios.Usage("Expects one or more mount paths as arguments")
+	}
+
+	var statuses []nagios.Status
+	var summaries []string
+	var perfdata []nagios.Perfdata
+	for _, path := range paths {
+		// An unmounted mount point would otherwise report the filesystem
+		// of its parent
+		if !*anyPath {
+			if err := checkMountPoint(path); err != nil {
+				statuses = append(statuses, nagios.Unknown)
+				summaries = append(summaries, err.Error())
+				continue
+			}
+		}
+		disk, err := sysinfo.ReadDisk(path)
+		if err != nil {
+			statuses = append(statuses, nagios.Unknown)
+			summaries = append(summaries, fmt.Sprintf("%v: %v", path, err))
+			continue
+		}
+
+		total := float64(disk.Total)
+		free := float64(disk.Free)
+		used := float64(disk.Used)
+		freePercent := 0.0
+		if total > 0 {
+			freePercent = free * 100 / total
+		}
+
+		status := nagios.OK
+		switch {
+		case crit.set() && free < crit.minFree(total):
+			status = nagios.Critical
+		case warn.set() && free < warn.minFree(total):
+			status = nagios.Warning
+		}
+		statuses = append(statuses, status)
+		summaries = append(summaries, fmt.Sprintf("%v %v MB used, %v MB free (%.0f%%) of %v MB",
+			path, int64(used/mb), int64(free/mb), freePercent, int64(total/mb)))
+
+		// Perfdata is in used space, so the thresholds become maximums of used space
+		p := nagios.Perfdata{
+			Label: path,
+			Value: float64(int64(used / mb)),
+			UOM:   "MB",
+			Min:   "0",
+			Max:   strconv.FormatInt(int64(total/mb), 10),
+		}
+		if warn.set() {
+			p.Warn = maxUsed(total, warn.minFree(total))
+		}
+		if crit.set() {
+			p.Crit = maxUsed(total, crit.minFree(total))
+		}
+		perfdata = append(perfdata, p)
+	}
+
+	nagios.Result{
+		Status:   nagios.Worst(statuses...),
+		Summary:  strings.Join(summaries, " ; "),
+		Perfdata: perfdata,
+	}.Exit()
+}
+
+// maxUsed formats the used space in MB matching a minimum of free bytes
+func maxUsed(total, minFree float64) string {
+	if minFree > total {
+		minFree = total
+	}
+	return strconv.FormatInt(int64((total-minFree)/mb), 10)
+}
+
+// checkMountPoint fails when path does not exist or is not the root of a
+// mounted filesystem, i.e. it lives on the same device as its parent
+func checkMountPoint(path string) error {
+	dev, err := sysinfo.Device(path)
+	if err != nil {
+		return fmt.Errorf("%v: %v", path, err)
+	}
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return fmt.Errorf("%v: %v", path, err)
+	}
+	if abs == "/" {
+		return nil
+	}
+	parent, err := sysinfo.Device(filepath.Dir(abs))
+	if err != nil {
+		return fmt.Errorf("%v: %v", path, err)
+	}
+	if dev == parent {
+		return fmt.Errorf("%v is not a mount point", path)
+	}
+	return nil
+}
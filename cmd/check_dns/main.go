@@ -0,0 +1,149 @@
+package main
+
+// Checks a DNS name resolves, optionally to an expected set of records
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/desylva/nagios/internal/nagios"
+	"github.com/desylva/nagios/internal/netcheck"
+)
+
+func main() {
+	record := flag.String("record", "A", "record type to query: A, AAAA, CNAME, MX or TXT")
+	server := flag.String("server", "", "resolver to query as host[:port] (default: system resolver)")
+	expect := flag.String("expect", "", "comma-separated records expected in the answer, in any order. MX records are given by host name")
+	timeout := nagios.DurationFlag{Duration: 10 * time.Second}
+	flag.Var(&timeout, "t", "query timeout, as a duration or in seconds")
+	flag.Var(&timeout, "timeout", "query timeout, as a duration or in seconds")
+	nagios.ParseFlags()
+
+	args := flag.Args()
+	if len(args) < 1 {
+		nagios.Usage("Expects 'name' as first argument")
+	}
+	name := args[0]
+	*record = strings.ToUpper(*record)
+
+	resolver := netcheck.Resolver(*server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout.Duration)
+	defer cancel()
+
+	start := time.Now()
+	records, err := lookup(ctx, resolver, *record, netcheck.FQDN(name))
+	elapsed := time.Since(start).Round(time.Millisecond).Seconds()
+	if err == errUnsupportedRecord {
+		nagios.Usage("Unsupported record type %v", *record)
+	}
+	if err != nil {
+		nagios.Result{Status: nagios.Critical, Summary: err.Error()}.Exit()
+	}
+
+	perfdata := []nagios.Perfdata{{
+		Label: "time",
+		Value: elapsed,
+		UOM:   "s",
+		Min:   "0",
+	}}
+	actual := strings.Join(records, ", ")
+
+	if *expect != "" {
+		var expected []string
+		for _, e := range strings.Split(*expect, ",") {
+			expected = append(expected, normalizeRecord(e))
+		}
+		if !sameSet(expected, records) {
+			nagios.Result{
+				Status:   nagios.Critical,
+				Summary:  fmt.Sprintf("%v %v . Expected records: %v . Returns records %v !", name, *record, strings.Join(expected, ", "), actual),
+				Perfdata: perfdata,
+			}.Exit()
+		}
+	}
+
+	nagios.Result{
+		Status:   nagios.OK,
+		Summary:  fmt.Sprintf("%v %v returns %v", name, *record, actual),
+		Perfdata: perfdata,
+	}.Exit()
+}
+
+var errUnsupportedRecord = errors.New("unsupported record type")
+
+// lookup resolves name for the given record type, returning the answers
+// as normalized strings
+func lookup(ctx context.Context, r *net.Resolver, record, name string) ([]string, error) {
+	var records []string
+	switch record {
+	case "A", "AAAA":
+		network := "ip4"
+		if record == "AAAA" {
+			network = "ip6"
+		}
+		ips, err := r.LookupIP(ctx, network, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, ip := range ips {
+			records = append(records, ip.String())
+		}
+	case "CNAME":
+		cname, err := r.LookupCNAME(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, normalizeRecord(cname))
+	case "MX":
+		mxs, err := r.LookupMX(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		for _, mx := range mxs {
+			records = append(records, normalizeRecord(mx.Host))
+		}
+	case "TXT":
+		txts, err := r.LookupTXT(ctx, name)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, txts...)
+	default:
+		return nil, errUnsupportedRecord
+	}
+	return records, nil
+}
+
+// normalizeRecord trims spaces and the trailing dot of fully qualified names,
+// and formats addresses canonically
+func normalizeRecord(s string) string {
+	s = strings.TrimSpace(s)
+	if ip := net.ParseIP(s); ip != nil {
+		return ip.String()
+	}
+	return strings.TrimSuffix(s, ".")
+}
+
+// sameSet reports whether a and b hold the same records, ignoring order
+func sameSet(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,157 @@
+package main
+
+// Checks an IP address is not listed in DNS blacklists
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/desylva/nagios/internal/nagios"
+	"github.com/desylva/nagios/internal/netcheck"
+)
+
+// listing is the answer of a blacklist zone for the address
+type listing struct {
+	zone    string
+	listed  bool
+	codes   []string
+	reasons []string
+	err     error
+}
+
+// reverse returns the fully qualified lookup name of ip in zone, like
+// 2.0.0.127.zen.spamhaus.org. for 127.0.0.2, or the reversed nibbles for an
+// IPv6 address. Trying the search domains could turn a miss into a listing.
+func reverse(ip net.IP, zone string) string {
+	var parts []string
+	if ip4 := ip.To4(); ip4 != nil {
+		for i := len(ip4) - 1; i >= 0; i-- {
+			parts = append(parts, strconv.Itoa(int(ip4[i])))
+		}
+	} else {
+		for i := len(ip) - 1; i >= 0; i-- {
+			parts = append(parts, strconv.FormatInt(int64(ip[i]&0xf), 16), strconv.FormatInt(int64(ip[i]>>4), 16))
+		}
+	}
+	return netcheck.FQDN(strings.Join(parts, ".") + "." + zone)
+}
+
+// query looks up ip in zone. A missing name means the address is not
+// listed, while any other resolver error is returned.
+func query(ctx context.Context, r *net.Resolver, ip net.IP, zone string) listing {
+	name := reverse(ip, zone)
+	result := listing{zone: zone}
+	codes, err := r.LookupHost(ctx, name)
+	var dnsErr *net.DNSError
+	if errors.As(err, &dnsErr) && dnsErr.IsNotFound {
+		return result
+	}
+	if err != nil {
+		result.err = err
+		return result
+	}
+	result.listed, result.codes = true, codes
+	// The reason is optional, a failed TXT lookup does not undo the listing
+	result.reasons, _ = r.LookupTXT(ctx, name)
+	return result
+}
+
+func main() {
+	severity := flag.String("severity", "critical", "status of a listed address: warning or critical")
+	server := flag.String("server", "", "resolver to query as host[:port] (default: system resolver)")
+	timeout := nagios.DurationFlag{Duration: 10 * time.Second}
+	flag.Var(&timeout, "t", "overall timeout, as a duration or in seconds")
+	flag.Var(&timeout, "timeout", "overall timeout, as a duration or in seconds")
+	nagios.ParseFlags()
+
+	listedStatus := nagios.Critical
+	switch strings.ToLower(*severity) {
+	case "critical":
+	case "warning":
+		listedStatus = nagios.Warning
+	default:
+		nagios.Usage("Invalid --severity %q, expects warning or critical", *severity)
+	}
+
+	args := flag.Args()
+	if len(args) < 2 {
+		nagios.Usage("Expects 'ip' and one or more blacklist zones like zen.spamhaus.org as arguments")
+	}
+	ip := net.ParseIP(args[0])
+	if ip == nil {
+		nagios.Usage("Invalid ip %q", args[0])
+	}
+	zones := args[1:]
+
+	resolver := netcheck.Resolver(*server)
+
+	ctx, cancel := context.WithTimeout(context.Background(), timeout.Duration)
+	defer cancel()
+
+	start := time.Now()
+	listings := make([]listing, len(zones))
+	var wg sync.WaitGroup
+	for i, zone := range zones {
+		wg.Add(1)
+		go func(i int, zone string) {
+			defer wg.Done()
+			listings[i] = query(ctx, resolver, ip, zone)
+		}(i, zone)
+	}
+	wg.Wait()
+	elapsed := time.Since(start).Round(time.Millisecond).Seconds()
+
+	status := nagios.OK
+	var listedIn []string
+	var details []string
+	failed := 0
+	for _, l := range listings {
+		switch {
+		case l.err != nil:
+			status = nagios.Worst(status, nagios.Unknown)
+			failed++
+			details = append(details, fmt.Sprintf("%v: lookup failed: %v", l.zone, l.err))
+		case l.listed:
+			status = nagios.Worst(status, listedStatus)
+			listedIn = append(listedIn, l.zone)
+			detail := fmt.Sprintf("%v: listed as %v", l.zone, strings.Join(l.codes, ", "))
+			if len(l.reasons) > 0 {
+				detail += " . " + strings.Join(l.reasons, " ")
+			}
+			details = append(details, detail)
+		}
+	}
+
+	var summary string
+	switch {
+	case len(listedIn) > 0:
+		summary = fmt.Sprintf("%v listed in %v of %v blacklists: %v", ip, len(listedIn), len(zones), strings.Join(listedIn, ", "))
+	case failed > 0:
+		summary = fmt.Sprintf("%v not listed in %v of %v blacklists, %v lookups failed", ip, len(zones)-failed, len(zones), failed)
+	default:
+		summary = fmt.Sprintf("%v not listed in any of %v blacklists", ip, len(zones))
+	}
+	nagios.Result{
+		Status:  status,
+		Summary: summary,
+		Perfdata: []nagios.Perfdata{{
+			Label: "listed",
+			Value: float64(len(listedIn)),
+			Min:   "0",
+			Max:   strconv.Itoa(len(zones)),
+		}, {
+			Label: "time",
+			Value: elapsed,
+			UOM:   "s",
+			Min:   "0",
+		}},
+		Details: details,
+	}.Exit()
+}
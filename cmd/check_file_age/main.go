@@ -0,0 +1,103 @@
+package main
+
+// Checks a file, or the newest of a glob, is recent enough and large enough
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	"github.com/desylva/nagios/internal/nagios"
+)
+
+func main() {
+	warn := nagios.DurationFlag{Duration: 4 * time.Minute}
+	crit := nagios.DurationFlag{Duration: 10 * time.Minute}
+	flag.Var(&warn, "w", "warning when the file is older than this, as a duration or in seconds")
+	flag.Var(&crit, "c", "critical when the file is older than this, as a duration or in seconds")
+	minSize := flag.String("min-size", "", "critical when the file is smaller than this size, like 10M")
+	glob := flag.Bool("glob", false, "treat the argument as a glob and check the newest matching file")
+	nagios.ParseFlags()
+
+	args := flag.Args()
+	if len(args) < 1 {
+		nagios.Usage("Expects 'file' as first argument")
+	}
+	path := args[0]
+	minBytes := -1.0
+	if *minSize != "" {
+		var err error
+		if minBytes, err = nagios.ParseSize(*minSize); err != nil {
+			nagios.Usage("Invalid --min-size: %v", err)
+		}
+	}
+
+	var info os.FileInfo
+	if *glob {
+		matches, err := filepath.Glob(path)
+		if err != nil {
+			nagios.Usage("Invalid glob %q: %v", path, err)
+		}
+		for _, match := range matches {
+			matchInfo, err := os.Stat(match)
+			if err != nil || matchInfo.IsDir() {
+				continue
+			}
+			if info == nil || matchInfo.ModTime().After(info.ModTime()) {
+				info, path = matchInfo, match
+			}
+		}
+		if info == nil {
+			nagios.Result{Status: nagios.Critical, Summary: "No file matches " + path}.Exit()
+		}
+	} else {
+		var err error
+		info, err = os.Stat(path)
+		if os.IsNotExist(err) {
+			nagios.Result{Status: nagios.Critical, Summary: path + " does not exist"}.Exit()
+		}
+		if err != nil {
+			nagios.Result{Status: nagios.Critical, Summary: err.Error()}.Exit()
+		}
+	}
+
+	age := time.Since(info.ModTime()).Round(time.Second)
+	size := info.Size()
+	status := nagios.OK
+	switch {
+	case age > crit.Duration:
+		status = nagios.Critical
+	case age > warn.Duration:
+		status = nagios.Warning
+	}
+	summary := fmt.Sprintf("%v is %v old and %v bytes", path, age, size)
+	if minBytes >= 0 && float64(size) < minBytes {
+		status = nagios.Critical
+		summary += fmt.Sprintf(" . Expected at least %v !", *minSize)
+	}
+
+	sizePerfdata := nagios.Perfdata{
+		Label: "size",
+		Value: float64(size),
+		UOM:   "B",
+		Min:   "0",
+	}
+	if minBytes >= 0 {
+		sizePerfdata.Crit = strconv.FormatFloat(minBytes, 'f', 0, 64) + ":"
+	}
+	nagios.Result{
+		Status:  status,
+		Summary: summary,
+		Perfdata: []nagios.Perfdata{{
+			Label: "age",
+			Value: age.Seconds(),
+			UOM:   "s",
+			Warn:  strconv.FormatFloat(warn.Duration.Seconds(), 'f', -1, 64),
+			Crit:  strconv.FormatFloat(crit.Duration.Seconds(), 'f', -1, 64),
+			Min:   "0",
+		}, sizePerfdata},
+	}.Exit()
+}
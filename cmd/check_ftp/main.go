@@ -0,0 +1,164 @@
+package main
+
+// Checks an FTP server greets and accepts a login, optionally listing a directory
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"net"
+	"net/textproto"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/desylva/nagios/internal/nagios"
+	"github.com/desylva/nagios/internal/netcheck"
+)
+
+func main() {
+	port := flag.Int("p", 21, "port to connect to")
+	user := flag.String("user", "anonymous", "user to log in as")
+	pass := flag.String("pass", "nagios@", "password to log in with, better given in $CHECK_FTP_PASS to keep it off the command line")
+	list := flag.String("list", "", "directory to list over a passive data connection after login")
+	var warn, crit nagios.RangeFlag
+	flag.Var(&warn, "w", "warning threshold on connect and login time in seconds")
+	flag.Var(&crit, "c", "critical threshold on connect and login time in seconds")
+	timeout := nagios.DurationFlag{Duration: 10 * time.Second}
+	flag.Var(&timeout, "t", "connect and session timeout, as a duration or in seconds")
+	flag.Var(&timeout, "timeout", "connect and session timeout, as a duration or in seconds")
+	family := netcheck.AddFlags()
+	nagios.ParseFlags()
+	family.Validate()
+
+	args := flag.Args()
+	if len(args) < 1 {
+		nagios.Usage("Expects 'host' as first argument")
+	}
+	host := args[0]
+	address := net.JoinHostPort(host, strconv.Itoa(*port))
+
+	start := time.Now()
+	conn, err := family.Dial(address, timeout.Duration)
+	if err != nil {
+		netcheck.Failure(err, "connect", timeout.Duration, address).Exit()
+	}
+	conn.SetDeadline(start.Add(timeout.Duration))
+	text := textproto.NewConn(conn)
+	defer text.Close()
+
+	_, greeting, err := text.ReadResponse(220)
+	if err != nil {
+		netcheck.Failure(err, "greeting", timeout.Duration, address).Exit()
+	}
+
+	// 230 to USER means no password is needed
+	code, msg, err := netcheck.Command(text, 0, "USER %s", *user)
+	if err == nil && code != 230 {
+		if code != 331 {
+			err = &textproto.Error{Code: code, Msg: msg}
+		} else {
+			_, _, err = netcheck.Command(text, 230, "PASS %s", *pass)
+		}
+	}
+	if err != nil {
+		netcheck.Failure(err, "login as "+*user, timeout.Duration, address).Exit()
+	}
+	elapsed := time.Since(start).Round(time.Millisecond).Seconds()
+
+	summary := fmt.Sprintf("%v greets %v . Logged in as %v", address, netcheck.FirstLine(greeting), *user)
+	if *list != "" {
+		entries, err := listing(text, conn, family, *list, start.Add(timeout.Duration))
+		if err != nil {
+			netcheck.Failure(err, "listing "+*list, timeout.Duration, address).Exit()
+		}
+		summary += fmt.Sprintf(" . Listed %v entries in %v", entries, *list)
+	}
+	netcheck.Command(text, 221, "QUIT")
+
+	nagios.Result{
+		Status:  nagios.Compare(elapsed, warn.Range, crit.Range),
+		Summary: summary,
+		Perfdata: []nagios.Perfdata{{
+			Label: "time",
+			Value: elapsed,
+			UOM:   "s",
+			Warn:  warn.String(),
+			Crit:  crit.String(),
+			Min:   "0",
+		}},
+	}.Exit()
+}
+
+// listing lists dir over a passive data connection and returns the
+// number of entries
+func listing(text *textproto.Conn, conn net.Conn, family *netcheck.Family, dir string, deadline time.Time) (int, error) {
+	dataAddress, err := passive(text, conn)
+	if err != nil {
+		return 0, err
+	}
+	data, err := family.Dial(dataAddress, time.Until(deadline))
+	if err != nil {
+		return 0, err
+	}
+	defer data.Close()
+	data.SetDeadline(deadline)
+
+	// 125 when the data connection is already open, 150 when opening it
+	if _, _, err := netcheck.Command(text, 1, "LIST %s", dir); err != nil {
+		return 0, err
+	}
+	entries := 0
+	scanner := bufio.NewScanner(data)
+	for scanner.Scan() {
+		if strings.TrimSpace(scanner.Text()) != "" {
+			entries++
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return 0, err
+	}
+	data.Close()
+	if _, _, err := text.ReadResponse(2); err != nil {
+		return 0, err
+	}
+	return entries, nil
+}
+
+// passive enters passive mode and returns the address of the data
+// connection, with EPSV when PASV is refused
+func passive(text *textproto.Conn, conn net.Conn) (string, error) {
+	host, _, _ := net.SplitHostPort(conn.RemoteAddr().String())
+	_, msg, err := netcheck.Command(text, 227, "PASV")
+	if err != nil {
+		if _, ok := err.(*textproto.Error); !ok {
+			return "", err
+		}
+		// EPSV replies "229 Entering Extended Passive Mode (|||port|)"
+		if _, msg, err = netcheck.Command(text, 229, "EPSV"); err != nil {
+			return "", err
+		}
+		i, j := strings.Index(msg, "(|||"), strings.LastIndex(msg, "|)")
+		if i < 0 || j < i+4 {
+			return "", fmt.Errorf("invalid EPSV reply %q", msg)
+		}
+		return net.JoinHostPort(host, msg[i+4:j]), nil
+	}
+
+	// PASV replies "227 Entering Passive Mode (h1,h2,h3,h4,p1,p2)". The
+	// host is ignored, as servers behind NAT often announce a private one.
+	i, j := strings.Index(msg, "("), strings.LastIndex(msg, ")")
+	if i < 0 || j < i {
+		return "", fmt.Errorf("invalid PASV reply %q", msg)
+	}
+	fields := strings.Split(msg[i+1:j], ",")
+	if len(fields) != 6 {
+		return "", fmt.Errorf("invalid PASV reply %q", msg)
+	}
+	p1, err1 := strconv.Atoi(strings.TrimSpace(fields[4]))
+	p2, err2 := strconv.Atoi(strings.TrimSpace(fields[5]))
+	if err1 != nil || err2 != nil {
+		return "", fmt.Errorf("invalid PASV reply %q", msg)
+	}
+	return net.JoinHostPort(host, strconv.Itoa(p1<<8|p2)), nil
+}
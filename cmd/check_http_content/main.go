@@ -0,0 +1,141 @@
+package main
+
+// Checks the body of an URL matches, or does not match, a pattern, or has
+// an expected SHA-256 digest
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"flag"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/desylva/nagios/internal/httpcheck"
+	"github.com/desylva/nagios/internal/nagios"
+)
+
+func main() {
+	opts := httpcheck.AddFlags()
+	pattern := flag.String("regex", "", "regular expression the body must match")
+	invert := flag.Bool("invert", false, "the body must not match --regex")
+	expectSHA256 := flag.String("expect-sha256", "", "hex SHA-256 digest the body must have")
+	contentType := httpcheck.AddContentTypeFlags()
+	maxBytes := flag.Int64("max-bytes", 1<<20, "maximum number of body bytes read and matched")
+	nagios.ParseFlags()
+	opts.Family.Validate()
+
+	args := flag.Args()
+	if len(args) < 1 {
+		nagios.Usage("Expects 'target url' as first argument")
+	}
+	targetURL := args[0]
+
+	if *pattern == "" && *expectSHA256 == "" {
+		nagios.Usage("Expects a --regex to match or an --expect-sha256 digest")
+	}
+	var re *regexp.Regexp
+	var err error
+	if *pattern != "" {
+		if re, err = regexp.Compile(*pattern); err != nil {
+			nagios.Usage("Invalid --regex: %v", err)
+		}
+	}
+	*expectSHA256 = strings.ToLower(*expectSHA256)
+	if _, err := hex.DecodeString(*expectSHA256); err != nil || len(*expectSHA256) != 0 && len(*expectSHA256) != 2*sha256.Size {
+		nagios.Usage("Invalid --expect-sha256 %q, expects %v hex digits", *expectSHA256, 2*sha256.Size)
+	}
+
+	client := opts.Client()
+	req, err := opts.NewRequest("GET", targetURL, nil)
+	if err != nil {
+		nagios.Usage("Invalid target url: %v", err)
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		opts.Failure(err, targetURL).Exit()
+	}
+	// The digest is computed while reading, and the body only kept when
+	// it has to be matched
+	hash := sha256.New()
+	reader := io.TeeReader(io.LimitReader(resp.Body, *maxBytes), hash)
+	var body []byte
+	var size int64
+	if re != nil {
+		body, err = io.ReadAll(reader)
+		size = int64(len(body))
+	} else {
+		size, err = io.Copy(io.Discard, reader)
+	}
+	var truncated bool
+	if err == nil && *expectSHA256 != "" {
+		var next [1]byte
+		n, _ := io.ReadFull(resp.Body, next[:])
+		truncated = n > 0
+	}
+	resp.Body.Close()
+	elapsed := time.Since(start).Round(time.Millisecond).Seconds()
+	if err != nil {
+		opts.Failure(err, targetURL).Exit()
+	}
+
+	perfdata := []nagios.Perfdata{{
+		Label: "time",
+		Value: elapsed,
+		UOM:   "s",
+		Min:   "0",
+	}, {
+		Label: "size",
+		Value: float64(size),
+		UOM:   "B",
+		Min:   "0",
+	}}
+
+	if result, ok := httpcheck.Unauthorized(resp); ok {
+		result.Perfdata = perfdata
+		result.Exit()
+	}
+
+	if result, ok := contentType.Mismatch(resp); ok {
+		result.Perfdata = perfdata
+		result.Exit()
+	}
+
+	var summaries []string
+	if *expectSHA256 != "" {
+		if truncated {
+			nagios.Result{
+				Status:   nagios.Critical,
+				Summary:  fmt.Sprintf("Body of %v exceeds --max-bytes %v, its digest cannot be verified", resp.Request.URL, *maxBytes),
+				Perfdata: perfdata,
+			}.Exit()
+		}
+		digest := hex.EncodeToString(hash.Sum(nil))
+		if digest != *expectSHA256 {
+			nagios.Result{
+				Status:   nagios.Critical,
+				Summary:  fmt.Sprintf("Target url: %v . Expected sha256: %v . Returns sha256 %v !", resp.Request.URL, *expectSHA256, digest),
+				Perfdata: perfdata,
+			}.Exit()
+		}
+		summaries = append(summaries, fmt.Sprintf("Body of %v has sha256 %v", resp.Request.URL, digest))
+	}
+
+	if re != nil {
+		matched := re.Match(body)
+		state := "not found"
+		if matched {
+			state = "found"
+		}
+		summary := fmt.Sprintf("Pattern %q %v in %v", *pattern, state, resp.Request.URL)
+		if matched == *invert {
+			nagios.Result{Status: nagios.Critical, Summary: summary + fmt.Sprintf(" in %vs", elapsed), Perfdata: perfdata}.Exit()
+		}
+		summaries = append(summaries, summary)
+	}
+	summary := strings.Join(summaries, " . ") + contentType.Note(resp) + fmt.Sprintf(" in %vs", elapsed)
+	nagios.Result{Status: nagios.OK, Summary: summary + opts.Note(), Perfdata: perfdata}.Exit()
+}
@@ -0,0 +1,136 @@
+package main
+
+// Checks the headers of the final response of an URL match, or are absent
+
+import (
+	"flag"
+	"fmt"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/desylva/nagios/internal/httpcheck"
+	"github.com/desylva/nagios/internal/nagios"
+)
+
+// assertion is a header name and the regular expression its value must match
+type assertion struct {
+	name    string
+	pattern *regexp.Regexp
+}
+
+// assertions is a repeatable flag.Value of "Name: regex" assertions
+type assertions []assertion
+
+func (a *assertions) String() string {
+	return fmt.Sprint(len(*a), " assertions")
+}
+
+// Set adds a "Name: regex" assertion
+func (a *assertions) Set(s string) error {
+	i := strings.Index(s, ":")
+	if i <= 0 {
+		return fmt.Errorf("header %q is not in the form 'Name: regex'", s)
+	}
+	pattern, err := regexp.Compile(strings.TrimSpace(s[i+1:]))
+	if err != nil {
+		return fmt.Errorf("invalid regex in %q: %v", s, err)
+	}
+	*a = append(*a, assertion{name: strings.TrimSpace(s[:i]), pattern: pattern})
+	return nil
+}
+
+// names is a repeatable flag.Value of header names
+type names []string
+
+func (n *names) String() string {
+	return strings.Join(*n, ", ")
+}
+
+func (n *names) Set(s string) error {
+	*n = append(*n, strings.TrimSpace(s))
+	return nil
+}
+
+func main() {
+	opts := httpcheck.AddFlags()
+	var expected assertions
+	flag.Var(&expected, "header", "header of the final response as 'Name: regex', the value must match. May be repeated")
+	var absent names
+	flag.Var(&absent, "header-absent", "header name the final response must not have, may be repeated")
+	nagios.ParseFlags()
+	opts.Family.Validate()
+
+	args := flag.Args()
+	if len(args) < 1 {
+		nagios.Usage("Expects 'target url' as first argument")
+	}
+	targetURL := args[0]
+	if len(expected) == 0 && len(absent) == 0 {
+		nagios.Usage("Expects --header or --header-absent assertions")
+	}
+
+	client := opts.Client()
+	req, err := opts.NewRequest("GET", targetURL, nil)
+	if err != nil {
+		nagios.Usage("Invalid target url: %v", err)
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	elapsed := time.Since(start).Round(time.Millisecond).Seconds()
+	if err != nil {
+		opts.Failure(err, targetURL).Exit()
+	}
+	resp.Body.Close()
+
+	perfdata := []nagios.Perfdata{{
+		Label: "time",
+		Value: elapsed,
+		UOM:   "s",
+		Min:   "0",
+	}}
+
+	if result, ok := httpcheck.Unauthorized(resp); ok {
+		result.Perfdata = perfdata
+		result.Exit()
+	}
+
+	failures := check(resp.Header, expected, absent)
+	total := len(expected) + len(absent)
+	if len(failures) > 0 {
+		nagios.Result{
+			Status:   nagios.Critical,
+			Summary:  fmt.Sprintf("%v of %v header assertions failed on %v", len(failures), total, resp.Request.URL),
+			Perfdata: perfdata,
+			Details:  failures,
+		}.Exit()
+	}
+	nagios.Result{
+		Status:   nagios.OK,
+		Summary:  fmt.Sprintf("All %v header assertions pass on %v", total, resp.Request.URL) + opts.Note(),
+		Perfdata: perfdata,
+	}.Exit()
+}
+
+// check describes each assertion the header fails
+func check(header http.Header, expected assertions, absent names) []string {
+	var failures []string
+	for _, a := range expected {
+		values := header.Values(a.name)
+		if len(values) == 0 {
+			failures = append(failures, fmt.Sprintf("Header %v is missing . Expected value matching %v !", a.name, a.pattern))
+			continue
+		}
+		value := strings.Join(values, ", ")
+		if !a.pattern.MatchString(value) {
+			failures = append(failures, fmt.Sprintf("Header %v: %v . Expected value matching %v !", a.name, value, a.pattern))
+		}
+	}
+	for _, name := range absent {
+		if values := header.Values(name); len(values) > 0 {
+			failures = append(failures, fmt.Sprintf("Header %v: %v . Expected no such header !", name, strings.Join(values, ", ")))
+		}
+	}
+	return failures
+}
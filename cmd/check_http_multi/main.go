@@ -0,0 +1,218 @@
+package main
+
+// Checks many URLs redirect correctly, concurrently, returning the worst status
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/desylva/nagios/internal/httpcheck"
+	"github.com/desylva/nagios/internal/nagios"
+)
+
+// pair is a target url and the url it is expected to redirect to
+type pair struct {
+	target   string
+	expected string
+}
+
+// pairs is a repeatable flag.Value of "target,expected" pairs
+type pairs []pair
+
+func (p *pairs) String() string {
+	return fmt.Sprint(len(*p), " pairs")
+}
+
+// Set adds a "target,expected" pair
+func (p *pairs) Set(s string) error {
+	parsed, ok := parsePair(s)
+	if !ok {
+		return fmt.Errorf("pair %q is not in the form 'target,expected'", s)
+	}
+	*p = append(*p, parsed)
+	return nil
+}
+
+// parsePair splits "target,expected" or "target expected"
+func parsePair(s string) (pair, bool) {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\t'
+	})
+	if len(fields) != 2 {
+		return pair{}, false
+	}
+	return pair{target: fields[0], expected: fields[1]}, true
+}
+
+// readPairsFile reads a file of pairs as with readPairs
+func readPairsFile(path string) ([]pair, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return readPairs(f, path)
+}
+
+// readPairs reads one "target,expected" or "target expected" pair per
+// line, skipping blank lines and # comments. name is used in errors.
+func readPairs(r io.Reader, name string) ([]pair, error) {
+	var list []pair
+	scanner := bufio.NewScanner(r)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		p, ok := parsePair(line)
+		if !ok {
+			return nil, fmt.Errorf("%v:%v: expects 'target,expected'", name, n)
+		}
+		list = append(list, p)
+	}
+	return list, scanner.Err()
+}
+
+// outcome is the result of checking a single pair
+type outcome struct {
+	status  nagios.Status
+	summary string
+	elapsed float64
+	done    bool
+}
+
+// check requests the target and compares the final url to the expected one
+func check(opts *httpcheck.Options, client *http.Client, p pair) outcome {
+	req, err := opts.NewRequest("GET", p.target, nil)
+	if err != nil {
+		return outcome{status: nagios.Unknown, summary: fmt.Sprintf("Invalid target url %v: %v", p.target, err)}
+	}
+	start := time.Now()
+	resp, err := client.Do(req)
+	elapsed := time.Since(start).Round(time.Millisecond).Seconds()
+	if err != nil {
+		result := opts.Failure(err, p.target)
+		return outcome{status: result.Status, summary: result.Summary}
+	}
+	resp.Body.Close()
+
+	if result, ok := httpcheck.Unauthorized(resp); ok {
+		return outcome{status: result.Status, summary: result.Summary, elapsed: elapsed, done: true}
+	}
+	retrievedURL := resp.Request.URL.String()
+	if retrievedURL != p.expected {
+		return outcome{
+			status:  nagios.Critical,
+			summary: fmt.Sprintf("Target url: %v . Expected url: %v . Returns url %v !", p.target, p.expected, retrievedURL),
+			elapsed: elapsed,
+			done:    true,
+		}
+	}
+	if resp.StatusCode/100 != 2 {
+		return outcome{
+			status:  nagios.Critical,
+			summary: fmt.Sprintf("Target url: %v . Returns url %v with status %v !", p.target, retrievedURL, resp.StatusCode),
+			elapsed: elapsed,
+			done:    true,
+		}
+	}
+	return outcome{
+		status:  nagios.OK,
+		summary: fmt.Sprintf("Target url: %v . Returns url %v", p.target, retrievedURL),
+		elapsed: elapsed,
+		done:    true,
+	}
+}
+
+func main() {
+	opts := httpcheck.AddFlags()
+	var list pairs
+	flag.Var(&list, "pair", "'target,expected' urls to check, may be repeated")
+	file := flag.String("file", "", "file of 'target,expected' urls to check, one pair per line")
+	stdin := flag.Bool("stdin", false, "read 'target expected' urls to check from stdin, one pair per line, and list the result of each")
+	concurrency := flag.Int("concurrency", 8, "number of urls checked at the same time")
+	nagios.ParseFlags()
+	opts.Family.Validate()
+
+	if *file != "" {
+		fromFile, err := readPairsFile(*file)
+		if err != nil {
+			nagios.Usage("Cannot read --file: %v", err)
+		}
+		list = append(list, fromFile...)
+	}
+	if *stdin {
+		fromStdin, err := readPairs(os.Stdin, "stdin")
+		if err != nil {
+			nagios.Usage("Cannot read stdin: %v", err)
+		}
+		list = append(list, fromStdin...)
+	}
+	if len(list) == 0 {
+		nagios.Usage("Expects urls to check with --pair, --file or --stdin")
+	}
+	if *concurrency < 1 {
+		*concurrency = 1
+	}
+
+	client := opts.Client()
+	outcomes := make([]outcome, len(list))
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < *concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				outcomes[i] = check(opts, client, list[i])
+			}
+		}()
+	}
+	for i := range list {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	var statuses []nagios.Status
+	var details []string
+	failures := 0
+	var perfdata []nagios.Perfdata
+	for i, o := range outcomes {
+		statuses = append(statuses, o.status)
+		if o.status != nagios.OK {
+			failures++
+		}
+		if o.status != nagios.OK || *stdin {
+			details = append(details, o.status.String()+": "+o.summary)
+		}
+		if o.done {
+			// Urls would need quoting and a target may be listed twice, so
+			// labels are numbered in the order the pairs are given
+			perfdata = append(perfdata, nagios.Perfdata{
+				Label: fmt.Sprintf("url_%v", i+1),
+				Value: o.elapsed,
+				UOM:   "s",
+				Min:   "0",
+			})
+		}
+	}
+
+	summary := fmt.Sprintf("All %v urls redirect correctly", len(list))
+	if failures > 0 {
+		summary = fmt.Sprintf("%v of %v urls failed", failures, len(list))
+	}
+	nagios.Result{
+		Status:   nagios.Worst(statuses...),
+		Summary:  summary + opts.Note(),
+		Perfdata: perfdata,
+		Details:  details,
+	}.Exit()
+}
@@ -0,0 +1,100 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMain runs the check itself when the test binary is started by run
+func TestMain(m *testing.M) {
+	if os.Getenv("CHECK_HTTP_MULTI_TEST_MAIN") == "1" {
+		main()
+	}
+	os.Exit(m.Run())
+}
+
+// run executes the check with args and an empty config file, returning its
+// exit code and output
+func run(t *testing.T, args ...string) (int, string) {
+	t.Helper()
+	config := filepath.Join(t.TempDir(), "checks.conf")
+	if err := os.WriteFile(config, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(os.Args[0], append([]string{"--config", config}, args...)...)
+	cmd.Env = append(os.Environ(), "CHECK_HTTP_MULTI_TEST_MAIN=1")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), out.String()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return 0, out.String()
+}
+
+func TestPairs(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/a":
+			http.Redirect(w, r, "/ok", http.StatusFound)
+		case "/b":
+			http.Redirect(w, r, "/missing", http.StatusFound)
+		case "/missing":
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+	ok := srv.URL + "/a," + srv.URL + "/ok"
+	wrongURL := srv.URL + "/a," + srv.URL + "/other"
+	wrongStatus := srv.URL + "/b," + srv.URL + "/missing"
+	invalid := "http://[::1,http://[::1"
+
+	tests := []struct {
+		name    string
+		pairs   []string
+		code    int
+		summary string
+	}{
+		{"all ok", []string{ok, ok}, 0, "OK: All 2 urls redirect correctly"},
+		{"wrong url", []string{ok, wrongURL}, 2, "CRITICAL: 1 of 2 urls failed"},
+		{"wrong status", []string{wrongStatus, ok}, 2, "CRITICAL: 1 of 2 urls failed"},
+		{"unknown", []string{ok, invalid}, 3, "UNKNOWN: 1 of 2 urls failed"},
+		{"critical over unknown", []string{invalid, ok, wrongURL}, 2, "CRITICAL: 2 of 3 urls failed"},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			var args []string
+			for _, p := range test.pairs {
+				args = append(args, "--pair", p)
+			}
+			code, out := run(t, args...)
+			if code != test.code || !strings.HasPrefix(out, test.summary) {
+				t.Errorf("exit %v, want %v with %q: %v", code, test.code, test.summary, out)
+			}
+		})
+	}
+}
+
+func TestPerfdataLabels(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+	pair := srv.URL + "/?a=b," + srv.URL + "/?a=b"
+
+	_, out := run(t, "--pair", pair, "--pair", pair)
+	perfdata := strings.TrimSpace(out[strings.Index(out, "|")+1:])
+	fields := strings.Fields(perfdata)
+	if len(fields) != 2 || !strings.HasPrefix(fields[0], "url_1=") || !strings.HasPrefix(fields[1], "url_2=") {
+		t.Errorf("perfdata %q, want url_1 and url_2", perfdata)
+	}
+}
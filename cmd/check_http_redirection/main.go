@@ -0,0 +1,343 @@
+package main
+
+// Checks an URL redirects correctly to another
+
+import (
+	"bytes"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/cookiejar"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/desylva/nagios/internal/httpcheck"
+	"github.com/desylva/nagios/internal/nagios"
+)
+
+var errTooManyHops = errors.New("too many redirects")
+
+// formPattern matches bodies that look like name=value&name=value
+var formPattern = regexp.MustCompile(`^[\w.~%+-]+=[^&=]*(&[\w.~%+-]+=[^&=]*)*$`)
+
+func main() {
+	var warn, crit nagios.RangeFlag
+	flag.Var(&warn, "w", "warning threshold on response time in seconds")
+	flag.Var(&crit, "c", "critical threshold on response time in seconds")
+	opts := httpcheck.AddFlags()
+	maxHops := flag.Int("max-hops", 0, "maximum number of redirects to follow (default: no limit beyond the client's 10)")
+	expectChain := flag.String("expect-chain", "", "comma-separated list of the urls each redirect should go to, in order. Replaces the 'expected url' argument")
+	expectRegex := flag.Bool("expect-regex", false, "treat the expected url as a regular expression matched against the whole final url")
+	ignoreQuery := flag.Bool("ignore-query", false, "strip the query string from the final and expected urls before comparing them")
+	contentType := httpcheck.AddContentTypeFlags()
+	expectStatus := flag.String("expect-status", "2xx", "expected status code of the final response, as a code like 200 or a class like 2xx")
+	var headers httpcheck.Headers
+	flag.Var(&headers, "H", "request header as 'Name: Value', may be repeated")
+	retries := flag.Int("retries", 0, "number of times to retry the request on transient network errors")
+	retryDelay := nagios.DurationFlag{Duration: time.Second}
+	flag.Var(&retryDelay, "retry-delay", "delay before the first retry, doubled for each further retry")
+	var cookies httpcheck.Cookies
+	flag.Var(&cookies, "cookie", "request cookie as 'name=value', may be repeated")
+	useJar := flag.Bool("cookie-jar", false, "keep cookies set by responses for the following redirects")
+	var method string
+	flag.StringVar(&method, "X", "", "request method (default: GET, or POST with --data)")
+	flag.StringVar(&method, "method", "", "request method (default: GET, or POST with --data)")
+	data := flag.String("data", "", "request body")
+	dataFile := flag.String("data-file", "", "file holding the request body, - for stdin")
+	debug := flag.Bool("debug", false, "write the request and response of each hop to stderr")
+	nagios.ParseFlags()
+	opts.Family.Validate()
+
+	if !validStatusSpec(*expectStatus) {
+		nagios.Usage("Invalid --expect-status %q", *expectStatus)
+	}
+
+	// Without --expect-chain: target expected [host]
+	// With --expect-chain:    target [host]
+	args := flag.Args()
+	var targetURL, expectedURL, host string
+	var expectedChain []string
+	if *expectChain != "" {
+		if len(args) < 1 {
+			nagios.Usage("Expects 'target url' as first argument")
+		}
+		targetURL = args[0]
+		expectedChain = strings.Split(*expectChain, ",")
+		expectedURL = expectedChain[len(expectedChain)-1]
+		if len(args) > 1 {
+			host = args[1]
+		}
+	} else {
+		if len(args) < 2 {
+			nagios.Usage("Expects 'target url' and 'expected url' as first arguments")
+		}
+		targetURL = args[0]
+		expectedURL = args[1]
+		if len(args) > 2 {
+			host = args[2]
+		}
+	}
+
+	var expectedPattern *regexp.Regexp
+	if *expectRegex {
+		var err error
+		// Anchored so that a pattern like https://example.com/ does not
+		// match a redirect to https://evil.test/?https://example.com/
+		if expectedPattern, err = regexp.Compile("^(?:" + expectedURL + ")$"); err != nil {
+			nagios.Usage("Invalid expected url regex %q: %v", expectedURL, err)
+		}
+	} else if *ignoreQuery {
+		expectedURL = stripQuery(expectedURL)
+	}
+
+	hops := 0
+	var chain []string
+	client := opts.Client()
+	if *debug {
+		httpcheck.Debug(client, os.Stderr)
+	}
+	client.CheckRedirect = func(req *http.Request, via []*http.Request) error {
+		hops = len(via)
+		chain = append(chain, req.URL.String())
+		if *maxHops > 0 && hops > *maxHops {
+			return errTooManyHops
+		}
+		if *maxHops <= 0 && hops >= 10 {
+			return errors.New("stopped after 10 redirects")
+		}
+		return nil
+	}
+
+	var body []byte
+	var err error
+	switch {
+	case *data != "" && *dataFile != "":
+		nagios.Usage("Expects only one of --data and --data-file")
+	case *data != "":
+		body = []byte(*data)
+	case *dataFile == "-":
+		body, err = io.ReadAll(os.Stdin)
+	case *dataFile != "":
+		body, err = os.ReadFile(*dataFile)
+	}
+	if err != nil {
+		nagios.Usage("Cannot read --data-file: %v", err)
+	}
+	if method == "" {
+		method = "GET"
+		if body != nil {
+			method = "POST"
+		}
+	}
+
+	// A bytes.Reader lets the client replay the body on 307 and 308
+	// redirects, while 301, 302 and 303 switch to a GET without body
+	var bodyReader io.Reader
+	if body != nil {
+		bodyReader = bytes.NewReader(body)
+	}
+	req, err := opts.NewRequest(strings.ToUpper(method), targetURL, bodyReader)
+	if err != nil {
+		nagios.Usage("Invalid target url: %v", err)
+	}
+	if len(host) > 0 {
+		// The client ignores a Host entry in the header map
+		req.Host = host
+	}
+	headers.Apply(req)
+	if body != nil && req.Header.Get("Content-Type") == "" && formPattern.Match(body) {
+		req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	}
+	if *useJar {
+		// The client replaces the Cookie header with the jar on redirects,
+		// so the given cookies must start in the jar. Without a path the jar
+		// scopes them to the target's directory, and without a domain to
+		// the target host only.
+		for _, cookie := range cookies {
+			cookie.Path = "/"
+		}
+		jar, _ := cookiejar.New(nil)
+		jar.SetCookies(req.URL, cookies)
+		client.Jar = jar
+	} else {
+		for _, cookie := range cookies {
+			req.AddCookie(cookie)
+		}
+	}
+
+	var timing httpcheck.Timing
+	req = timing.Trace(req)
+
+	var resp *http.Response
+	var elapsed float64
+	var total time.Duration
+	attempts := 0
+	delay := retryDelay.Duration
+	for {
+		attempts++
+		hops, chain = 0, nil
+		timing.Reset()
+		if attempts > 1 && req.GetBody != nil {
+			req.Body, _ = req.GetBody()
+		}
+		start := time.Now()
+		resp, err = client.Do(req)
+		total = time.Since(start)
+		elapsed = total.Round(time.Millisecond).Seconds()
+		if err == nil || attempts > *retries || !httpcheck.Transient(err) {
+			break
+		}
+		time.Sleep(delay)
+		delay *= 2
+	}
+	var attemptsNote string
+	if attempts > 1 {
+		attemptsNote = fmt.Sprintf(" (%v attempts)", attempts)
+	}
+
+	if errors.Is(err, errTooManyHops) {
+		nagios.Result{
+			Status:  nagios.Critical,
+			Summary: fmt.Sprintf("%v redirects exceeds max of %v requesting %v", hops, *maxHops, targetURL),
+			Perfdata: []nagios.Perfdata{
+				{Label: "hops", Value: float64(hops), Min: "0"},
+			},
+		}.Exit()
+	}
+	if err != nil {
+		result := opts.Failure(err, targetURL)
+		result.Summary += attemptsNote
+		result.Perfdata = []nagios.Perfdata{
+			{Label: "hops", Value: float64(hops), Min: "0"},
+		}
+		result.Exit()
+	}
+	resp.Body.Close()
+
+	perfdata := []nagios.Perfdata{{
+		Label: "time",
+		Value: elapsed,
+		UOM:   "s",
+		Warn:  warn.String(),
+		Crit:  crit.String(),
+		Min:   "0",
+	}, {
+		Label: "hops",
+		Value: float64(hops),
+		Min:   "0",
+	}}
+	perfdata = append(perfdata, timing.Perfdata(total)...)
+
+	if result, ok := httpcheck.Unauthorized(resp); ok {
+		result.Perfdata = perfdata
+		result.Exit()
+	}
+
+	retrievedURL := resp.Request.URL.String()
+	comparedURL := retrievedURL
+	if *ignoreQuery {
+		comparedURL = stripQuery(retrievedURL)
+	}
+
+	if expectedChain != nil {
+		if diverged := compareChain(expectedChain, chain); diverged != "" {
+			nagios.Result{
+				Status:   nagios.Critical,
+				Summary:  fmt.Sprintf("Target url: %v . Redirect chain %v", targetURL, diverged),
+				Perfdata: perfdata,
+			}.Exit()
+		}
+	}
+
+	if expectedPattern != nil && !expectedPattern.MatchString(comparedURL) {
+		nagios.Result{
+			Status:   nagios.Critical,
+			Summary:  fmt.Sprintf("Target url: %v . Expected url matching %v . Returns url %v !", targetURL, expectedURL, retrievedURL),
+			Perfdata: perfdata,
+		}.Exit()
+	}
+	if expectedPattern == nil && comparedURL != expectedURL {
+		nagios.Result{
+			Status:   nagios.Critical,
+			Summary:  fmt.Sprintf("Target url: %v . Expected url: %v . Returns url %v !", targetURL, expectedURL, retrievedURL),
+			Perfdata: perfdata,
+		}.Exit()
+	}
+
+	if !matchStatus(*expectStatus, resp.StatusCode) {
+		nagios.Result{
+			Status:   nagios.Critical,
+			Summary:  fmt.Sprintf("Returns url %v with status %v . Expected status %v !", retrievedURL, resp.StatusCode, *expectStatus),
+			Perfdata: perfdata,
+		}.Exit()
+	}
+
+	if result, ok := contentType.Mismatch(resp); ok {
+		result.Perfdata = perfdata
+		result.Exit()
+	}
+
+	summary := "Returns url " + retrievedURL
+	switch {
+	case hops == 1:
+		summary += " after 1 redirect"
+	case hops > 1:
+		summary += fmt.Sprintf(" after %v redirects", hops)
+	}
+	summary += contentType.Note(resp)
+	status := nagios.Compare(elapsed, warn.Range, crit.Range)
+	if status != nagios.OK {
+		summary += fmt.Sprintf(" . Response time %vs out of range", elapsed)
+	}
+	summary += attemptsNote + opts.Note()
+	nagios.Result{Status: status, Summary: summary, Perfdata: perfdata}.Exit()
+}
+
+// compareChain describes the first hop where the followed redirects differ
+// from the expected ones, or returns "" when they match
+func compareChain(expected, actual []string) string {
+	for i := 0; i < len(expected) || i < len(actual); i++ {
+		switch {
+		case i >= len(actual):
+			return fmt.Sprintf("stops after hop %v . Expected hop %v to %v !", len(actual), i+1, expected[i])
+		case i >= len(expected):
+			return fmt.Sprintf("continues past hop %v . Hop %v goes to %v !", len(expected), i+1, actual[i])
+		case expected[i] != actual[i]:
+			return fmt.Sprintf("diverges at hop %v . Expected url: %v . Returns url %v !", i+1, expected[i], actual[i])
+		}
+	}
+	return ""
+}
+
+// stripQuery removes the query string of an url
+func stripQuery(u string) string {
+	if i := strings.IndexByte(u, '?'); i >= 0 {
+		return u[:i]
+	}
+	return u
+}
+
+// validStatusSpec reports whether spec is a status code or a class like 2xx
+func validStatusSpec(spec string) bool {
+	if len(spec) != 3 {
+		return false
+	}
+	if strings.HasSuffix(spec, "xx") {
+		return spec[0] >= '1' && spec[0] <= '5'
+	}
+	_, err := strconv.Atoi(spec)
+	return err == nil
+}
+
+// matchStatus reports whether code satisfies a status spec like 200 or 2xx
+func matchStatus(spec string, code int) bool {
+	if strings.HasSuffix(spec, "xx") {
+		return code/100 == int(spec[0]-'0')
+	}
+	return strconv.Itoa(code) == spec
+}
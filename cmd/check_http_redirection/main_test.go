@@ -0,0 +1,123 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// TestMain runs the check itself when the test binary is started by run
+func TestMain(m *testing.M) {
+	if os.Getenv("CHECK_HTTP_REDIRECTION_TEST_MAIN") == "1" {
+		main()
+	}
+	os.Exit(m.Run())
+}
+
+// run executes the check with args and an empty config file, returning its
+// exit code and output
+func run(t *testing.T, args ...string) (int, string) {
+	t.Helper()
+	config := filepath.Join(t.TempDir(), "checks.conf")
+	if err := os.WriteFile(config, nil, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cmd := exec.Command(os.Args[0], append([]string{"--config", config}, args...)...)
+	cmd.Env = append(os.Environ(), "CHECK_HTTP_REDIRECTION_TEST_MAIN=1")
+	var out bytes.Buffer
+	cmd.Stdout = &out
+	err := cmd.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode(), out.String()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	return 0, out.String()
+}
+
+func TestHostOverride(t *testing.T) {
+	var hosts []string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		hosts = append(hosts, r.Host)
+		if r.URL.Path == "/" {
+			http.Redirect(w, r, "/final", http.StatusFound)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		name string
+		args []string
+	}{
+		{"argument", []string{srv.URL + "/", srv.URL + "/final", "example.test"}},
+		{"header", []string{"-H", "Host: example.test", srv.URL + "/", srv.URL + "/final"}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			hosts = nil
+			code, out := run(t, test.args...)
+			if code != 0 || !strings.HasPrefix(out, "OK") {
+				t.Fatalf("exit %v: %v", code, out)
+			}
+			if len(hosts) == 0 || hosts[0] != "example.test" {
+				t.Errorf("server received hosts %q, want example.test first", hosts)
+			}
+		})
+	}
+}
+
+func TestCookieJar(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, err := r.Cookie("session"); err != nil {
+			http.Redirect(w, r, "/login", http.StatusFound)
+			return
+		}
+		if r.URL.Path == "/app/start" {
+			http.Redirect(w, r, "/home", http.StatusFound)
+		}
+	}))
+	defer srv.Close()
+
+	for _, args := range [][]string{
+		{"--cookie", "session=abc", srv.URL + "/app/start", srv.URL + "/home"},
+		{"--cookie", "session=abc", "--cookie-jar", srv.URL + "/app/start", srv.URL + "/home"},
+	} {
+		code, out := run(t, args...)
+		if code != 0 || !strings.HasPrefix(out, "OK") {
+			t.Errorf("%q: exit %v: %v", args, code, out)
+		}
+	}
+}
+
+func TestExpectRegex(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/" {
+			http.Redirect(w, r, "/final/page?from=/home", http.StatusFound)
+		}
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		pattern string
+		code    int
+	}{
+		{`.*/final/page\?from=/home`, 0},
+		{`.*/final/.*`, 0},
+		{`.*from=`, 2},
+		{`/final`, 2},
+	}
+	for _, test := range tests {
+		code, out := run(t, "--expect-regex", srv.URL+"/", test.pattern)
+		if code != test.code {
+			t.Errorf("pattern %q: exit %v, want %v: %v", test.pattern, code, test.code, out)
+		}
+	}
+}
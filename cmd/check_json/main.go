@@ -0,0 +1,208 @@
+package main
+
+// Checks a field of a JSON document served by an URL
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/desylva/nagios/internal/httpcheck"
+	"github.com/desylva/nagios/internal/nagios"
+)
+
+func main() {
+	opts := httpcheck.AddFlags()
+	path := flag.String("path", "$", "path of the field to check, e.g. $.db.lag or $.items[0].name")
+	var warn, crit nagios.RangeFlag
+	flag.Var(&warn, "w", "warning threshold on the numeric field")
+	flag.Var(&crit, "c", "critical threshold on the numeric field")
+	expectString := flag.String("expect-string", "", "value the field must be equal to")
+	maxBytes := flag.Int64("max-bytes", 1<<20, "maximum number of body bytes read")
+	nagios.ParseFlags()
+	opts.Family.Validate()
+
+	args := flag.Args()
+	if len(args) < 1 {
+		nagios.Usage("Expects 'target url' as first argument")
+	}
+	targetURL := args[0]
+
+	segments, err := parsePath(*path)
+	if err != nil {
+		nagios.Usage("Invalid --path: %v", err)
+	}
+
+	client := opts.Client()
+	req, err := opts.NewRequest("GET", targetURL, nil)
+	if err != nil {
+		nagios.Usage("Invalid target url: %v", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	start := time.Now()
+	resp, err := client.Do(req)
+	if err != nil {
+		opts.Failure(err, targetURL).Exit()
+	}
+	body, err := io.ReadAll(io.LimitReader(resp.Body, *maxBytes))
+	resp.Body.Close()
+	elapsed := time.Since(start).Round(time.Millisecond).Seconds()
+	if err != nil {
+		opts.Failure(err, targetURL).Exit()
+	}
+
+	perfdata := []nagios.Perfdata{{
+		Label: "time",
+		Value: elapsed,
+		UOM:   "s",
+		Min:   "0",
+	}}
+
+	if result, ok := httpcheck.Unauthorized(resp); ok {
+		result.Perfdata = perfdata
+		result.Exit()
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		nagios.Result{
+			Status:   nagios.Critical,
+			Summary:  fmt.Sprintf("%v returns status %v", targetURL, resp.StatusCode),
+			Perfdata: perfdata,
+		}.Exit()
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal(body, &doc); err != nil {
+		nagios.Result{
+			Status:   nagios.Critical,
+			Summary:  fmt.Sprintf("%v returns invalid JSON: %v", targetURL, err),
+			Perfdata: perfdata,
+		}.Exit()
+	}
+
+	value, ok := lookupPath(doc, segments)
+	if !ok {
+		nagios.Result{
+			Status:   nagios.Unknown,
+			Summary:  fmt.Sprintf("Path %v not found in %v", *path, targetURL),
+			Perfdata: perfdata,
+		}.Exit()
+	}
+
+	text := formatValue(value)
+	summary := fmt.Sprintf("%v is %v", *path, text)
+
+	if *expectString != "" && text != *expectString {
+		nagios.Result{
+			Status:   nagios.Critical,
+			Summary:  fmt.Sprintf("%v . Expected %v !", summary, *expectString),
+			Perfdata: perfdata,
+		}.Exit()
+	}
+
+	status := nagios.OK
+	if number, isNumber := value.(float64); isNumber {
+		label := strings.TrimPrefix(strings.TrimPrefix(*path, "$"), ".")
+		if label == "" {
+			label = "value"
+		}
+		perfdata = append(perfdata, nagios.Perfdata{
+			Label: label,
+			Value: number,
+			Warn:  warn.String(),
+			Crit:  crit.String(),
+		})
+		status = nagios.Compare(number, warn.Range, crit.Range)
+	} else if warn.Range != nil || crit.Range != nil {
+		nagios.Result{
+			Status:   nagios.Unknown,
+			Summary:  fmt.Sprintf("%v is not numeric (%v), cannot apply thresholds", *path, text),
+			Perfdata: perfdata,
+		}.Exit()
+	}
+
+	nagios.Result{Status: status, Summary: summary + opts.Note(), Perfdata: perfdata}.Exit()
+}
+
+// parsePath splits a path like $.db.replicas[0].lag into object keys
+// (strings) and array indexes (ints)
+func parsePath(path string) ([]interface{}, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("%q does not start with $", path)
+	}
+	var segments []interface{}
+	rest := path[1:]
+	for rest != "" {
+		switch rest[0] {
+		case '.':
+			end := strings.IndexAny(rest[1:], ".[")
+			if end < 0 {
+				end = len(rest) - 1
+			}
+			key := rest[1 : end+1]
+			if key == "" {
+				return nil, fmt.Errorf("%q has an empty key", path)
+			}
+			segments = append(segments, key)
+			rest = rest[end+1:]
+		case '[':
+			end := strings.Index(rest, "]")
+			if end < 0 {
+				return nil, fmt.Errorf("%q has an unterminated [", path)
+			}
+			inner := rest[1:end]
+			if unquoted, err := strconv.Unquote(inner); err == nil {
+				segments = append(segments, unquoted)
+			} else if index, err := strconv.Atoi(inner); err == nil && index >= 0 {
+				segments = append(segments, index)
+			} else {
+				return nil, fmt.Errorf("%q has an invalid index [%v]", path, inner)
+			}
+			rest = rest[end+1:]
+		default:
+			return nil, fmt.Errorf("%q has an unexpected %q", path, rest[0])
+		}
+	}
+	return segments, nil
+}
+
+// lookupPath walks the decoded document along the path segments
+func lookupPath(doc interface{}, segments []interface{}) (interface{}, bool) {
+	for _, segment := range segments {
+		switch key := segment.(type) {
+		case string:
+			object, ok := doc.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if doc, ok = object[key]; !ok {
+				return nil, false
+			}
+		case int:
+			array, ok := doc.([]interface{})
+			if !ok || key >= len(array) {
+				return nil, false
+			}
+			doc = array[key]
+		}
+	}
+	return doc, true
+}
+
+// formatValue renders a decoded JSON value for the summary
+func formatValue(value interface{}) string {
+	switch v := value.(type) {
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		b, _ := json.Marshal(v)
+		return string(b)
+	}
+}
@@ -0,0 +1,112 @@
+package main
+
+// Checks the 1, 5 and 15 minute load averages of the local host
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/desylva/nagios/internal/nagios"
+	"github.com/desylva/nagios/internal/sysinfo"
+)
+
+var windows = [3]string{"load1", "load5", "load15"}
+
+// loadThreshold holds one maximum per load average window
+type loadThreshold struct {
+	spec   string
+	limits [3]float64
+}
+
+func (t *loadThreshold) String() string {
+	return t.spec
+}
+
+// Set parses "5,4,3" for the 1, 5 and 15 minute windows, or a single value
+// applying to all of them
+func (t *loadThreshold) Set(s string) error {
+	parts := strings.Split(s, ",")
+	if len(parts) != 1 && len(parts) != 3 {
+		return fmt.Errorf("threshold %q is not in the form 'load1,load5,load15'", s)
+	}
+	for i := range t.limits {
+		part := parts[0]
+		if len(parts) == 3 {
+			part = parts[i]
+		}
+		v, err := strconv.ParseFloat(part, 64)
+		if err != nil {
+			return fmt.Errorf("invalid load in %q: %v", s, err)
+		}
+		t.limits[i] = v
+	}
+	t.spec = s
+	return nil
+}
+
+// breached returns the windows whose load is above the threshold
+func (t *loadThreshold) breached(load [3]float64) []string {
+	if t.spec == "" {
+		return nil
+	}
+	var windowNames []string
+	for i := range load {
+		if load[i] > t.limits[i] {
+			windowNames = append(windowNames, windows[i])
+		}
+	}
+	return windowNames
+}
+
+func main() {
+	var warn, crit loadThreshold
+	flag.Var(&warn, "w", "warning thresholds as 'load1,load5,load15'")
+	flag.Var(&crit, "c", "critical thresholds as 'load1,load5,load15'")
+	perCPU := flag.Bool("per-cpu", false, "divide the load averages by the number of CPUs")
+	nagios.ParseFlags()
+
+	load, err := sysinfo.LoadAverage()
+	if err != nil {
+		nagios.Result{Status: nagios.Unknown, Summary: "Cannot read load averages: " + err.Error()}.Exit()
+	}
+	summary := "load average"
+	if *perCPU {
+		cpus := runtime.NumCPU()
+		for i := range load {
+			load[i] = math.Round(load[i]/float64(cpus)*100) / 100
+		}
+		summary = fmt.Sprintf("load average per CPU (%v CPUs)", cpus)
+	}
+
+	values := make([]string, len(load))
+	perfdata := make([]nagios.Perfdata, len(load))
+	for i := range load {
+		values[i] = strconv.FormatFloat(load[i], 'f', 2, 64)
+		perfdata[i] = nagios.Perfdata{
+			Label: windows[i],
+			Value: load[i],
+			Min:   "0",
+		}
+		if warn.spec != "" {
+			perfdata[i].Warn = strconv.FormatFloat(warn.limits[i], 'f', -1, 64)
+		}
+		if crit.spec != "" {
+			perfdata[i].Crit = strconv.FormatFloat(crit.limits[i], 'f', -1, 64)
+		}
+	}
+	summary += ": " + strings.Join(values, ", ")
+
+	status := nagios.OK
+	if breached := crit.breached(load); breached != nil {
+		status = nagios.Critical
+		summary += " . " + strings.Join(breached, ", ") + " above critical threshold"
+	} else if breached := warn.breached(load); breached != nil {
+		status = nagios.Warning
+		summary += " . " + strings.Join(breached, ", ") + " above warning threshold"
+	}
+	nagios.Result{Status: status, Summary: summary, Perfdata: perfdata}.Exit()
+}
@@ -0,0 +1,163 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+// Checks a log file for new lines matching a pattern since the last run
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"syscall"
+
+	"github.com/desylva/nagios/internal/nagios"
+)
+
+// state is the position reached in the log file by the last run
+type state struct {
+	inode  uint64
+	offset int64
+}
+
+// readState reads the state file, a zero state when there is none yet
+func readState(path string) (state, error) {
+	var s state
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return s, nil
+	}
+	if err != nil {
+		return s, err
+	}
+	if _, err := fmt.Sscanf(string(data), "%d %d", &s.inode, &s.offset); err != nil {
+		return s, fmt.Errorf("%v: invalid state: %v", path, err)
+	}
+	return s, nil
+}
+
+// writeState replaces the state file, through a temporary file so an
+// interrupted run does not leave it truncated
+func writeState(path string, s state) error {
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, []byte(fmt.Sprintf("%d %d\n", s.inode, s.offset)), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+// defaultStateFile names a state file in the temporary directory after the log file
+func defaultStateFile(logFile string) string {
+	abs, err := filepath.Abs(logFile)
+	if err != nil {
+		abs = logFile
+	}
+	name := strings.ReplaceAll(strings.Trim(abs, string(filepath.Separator)), string(filepath.Separator), "_")
+	return filepath.Join(os.TempDir(), "check_log."+name+".state")
+}
+
+func main() {
+	pattern := flag.String("pattern", "", "regular expression of the lines to report")
+	stateFile := flag.String("state-file", "", "file keeping the position reached by the last run (default: in the temporary directory, named after the log file)")
+	var warn, crit nagios.RangeFlag
+	flag.Var(&warn, "w", "warning threshold on the number of new matching lines")
+	flag.Var(&crit, "c", "critical threshold on the number of new matching lines (default: 0 when -w is not given either)")
+	nagios.ParseFlags()
+	// Any new matching line is critical unless the thresholds are given,
+	// so that -w alone does not escalate past WARNING
+	if warn.Range == nil && crit.Range == nil {
+		crit.Set("0")
+	}
+
+	args := flag.Args()
+	if len(args) < 1 {
+		nagios.Usage("Expects 'log file' as first argument")
+	}
+	logFile := args[0]
+	if *pattern == "" {
+		nagios.Usage("Expects a --pattern to match")
+	}
+	re, err := regexp.Compile(*pattern)
+	if err != nil {
+		nagios.Usage("Invalid --pattern: %v", err)
+	}
+	if *stateFile == "" {
+		*stateFile = defaultStateFile(logFile)
+	}
+
+	last, err := readState(*stateFile)
+	if err != nil {
+		nagios.Result{Status: nagios.Unknown, Summary: "Cannot read state: " + err.Error()}.Exit()
+	}
+	f, err := os.Open(logFile)
+	if err != nil {
+		nagios.Result{Status: nagios.Unknown, Summary: "Cannot open log: " + err.Error()}.Exit()
+	}
+	defer f.Close()
+	var st syscall.Stat_t
+	if err := syscall.Fstat(int(f.Fd()), &st); err != nil {
+		nagios.Result{Status: nagios.Unknown, Summary: "Cannot stat log: " + err.Error()}.Exit()
+	}
+
+	// A new inode or a shrunk file means the log was rotated or truncated
+	// since the last run, so it is scanned again from the start
+	current := state{inode: uint64(st.Ino)}
+	rotated := false
+	if last.inode == current.inode && last.offset <= st.Size {
+		current.offset = last.offset
+	} else if last.inode != 0 {
+		rotated = true
+	}
+	if _, err := f.Seek(current.offset, io.SeekStart); err != nil {
+		nagios.Result{Status: nagios.Unknown, Summary: "Cannot seek log: " + err.Error()}.Exit()
+	}
+
+	matches := 0
+	var first string
+	reader := bufio.NewReader(f)
+	for {
+		line, err := reader.ReadString('\n')
+		if err == io.EOF {
+			// A partial last line is read again once it is complete
+			break
+		}
+		if err != nil {
+			nagios.Result{Status: nagios.Unknown, Summary: "Cannot read log: " + err.Error()}.Exit()
+		}
+		current.offset += int64(len(line))
+		line = strings.TrimRight(line, "\r\n")
+		if re.MatchString(line) {
+			if matches == 0 {
+				first = line
+			}
+			matches++
+		}
+	}
+	if err := writeState(*stateFile, current); err != nil {
+		nagios.Result{Status: nagios.Unknown, Summary: "Cannot write state: " + err.Error()}.Exit()
+	}
+
+	summary := fmt.Sprintf("%v new lines matching %q in %v", matches, *pattern, logFile)
+	if rotated {
+		summary += " (rotated, scanned from the start)"
+	}
+	if matches > 0 {
+		summary += " . First: " + first
+	}
+	nagios.Result{
+		Status:  nagios.Compare(float64(matches), warn.Range, crit.Range),
+		Summary: summary,
+		Perfdata: []nagios.Perfdata{{
+			Label: "matches",
+			Value: float64(matches),
+			Warn:  warn.String(),
+			Crit:  crit.String(),
+			Min:   "0",
+		}},
+	}.Exit()
+}
@@ -0,0 +1,136 @@
+package main
+
+// Checks the memory usage of the local host, optionally including swap
+
+import (
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/desylva/nagios/internal/nagios"
+	"github.com/desylva/nagios/internal/sysinfo"
+)
+
+// usedThreshold is a maximum of used memory, in percent or in bytes
+type usedThreshold struct {
+	spec    string
+	percent bool
+	value   float64
+}
+
+func (t *usedThreshold) String() string {
+	return t.spec
+}
+
+// Set parses a used memory like "90%" or "14G"
+func (t *usedThreshold) Set(s string) error {
+	t.spec = s
+	t.percent = strings.HasSuffix(s, "%")
+	var v float64
+	var err error
+	if t.percent {
+		v, err = strconv.ParseFloat(strings.TrimSuffix(s, "%"), 64)
+	} else {
+		v, err = nagios.ParseSize(s)
+	}
+	if err != nil || v < 0 {
+		return fmt.Errorf("invalid used memory %q, expects a percentage like 90%% or a size like 14G", s)
+	}
+	t.value = v
+	return nil
+}
+
+// maxUsed returns the threshold in used bytes out of total bytes, or -1
+// when no threshold was given
+func (t *usedThreshold) maxUsed(total float64) float64 {
+	switch {
+	case t.spec == "":
+		return -1
+	case t.percent:
+		return total * t.value / 100
+	}
+	return t.value
+}
+
+const mb = 1 << 20
+
+// evaluate returns the status of used bytes out of total bytes
+func evaluate(used, total float64, warn, crit *usedThreshold) nagios.Status {
+	if limit := crit.maxUsed(total); limit >= 0 && used >= limit {
+		return nagios.Critical
+	}
+	if limit := warn.maxUsed(total); limit >= 0 && used >= limit {
+		return nagios.Warning
+	}
+	return nagios.OK
+}
+
+// mbThreshold formats the threshold in MB for perfdata
+func mbThreshold(t *usedThreshold, total float64) string {
+	limit := t.maxUsed(total)
+	if limit < 0 {
+		return ""
+	}
+	return strconv.FormatInt(int64(limit/mb), 10)
+}
+
+func main() {
+	var warn, crit usedThreshold
+	flag.Var(&warn, "w", "warning when used memory reaches this percentage (90%) or size (14G)")
+	flag.Var(&crit, "c", "critical when used memory reaches this percentage (95%) or size (15G)")
+	checkSwap := flag.Bool("check-swap", false, "also apply the thresholds to the swap usage")
+	nagios.ParseFlags()
+
+	mem, err := sysinfo.ReadMemory()
+	if err != nil {
+		nagios.Result{Status: nagios.Unknown, Summary: "Cannot read memory usage: " + err.Error()}.Exit()
+	}
+
+	total := float64(mem.Total)
+	available := float64(mem.Available)
+	used := total - available
+	swapTotal := float64(mem.SwapTotal)
+	swapUsed := swapTotal - float64(mem.SwapFree)
+
+	status := evaluate(used, total, &warn, &crit)
+	summary := fmt.Sprintf("%v MB used, %v MB available (%.0f%%) of %v MB",
+		int64(used/mb), int64(available/mb), available*100/total, int64(total/mb))
+	if swapTotal > 0 {
+		summary += fmt.Sprintf(" . Swap %v MB used of %v MB", int64(swapUsed/mb), int64(swapTotal/mb))
+		if *checkSwap {
+			status = nagios.Worst(status, evaluate(swapUsed, swapTotal, &warn, &crit))
+		}
+	}
+
+	swapPerfdata := nagios.Perfdata{
+		Label: "swap",
+		Value: float64(int64(swapUsed / mb)),
+		UOM:   "MB",
+		Min:   "0",
+		Max:   strconv.FormatInt(int64(swapTotal/mb), 10),
+	}
+	if *checkSwap {
+		swapPerfdata.Warn = mbThreshold(&warn, swapTotal)
+		swapPerfdata.Crit = mbThreshold(&crit, swapTotal)
+	}
+	nagios.Result{
+		Status:  status,
+		Summary: summary,
+		Perfdata: []nagios.Perfdata{{
+			Label: "used",
+			Value: float64(int64(used / mb)),
+			UOM:   "MB",
+			Warn:  mbThreshold(&warn, total),
+			Crit:  mbThreshold(&crit, total),
+			Min:   "0",
+			Max:   strconv.FormatInt(int64(total/mb), 10),
+		}, {
+			Label: "available",
+			Value: float64(int64(available / mb)),
+			UOM:   "MB",
+			Min:   "0",
+			Max:   strconv.FormatInt(int64(total/mb), 10),
+		}, swapPerfdata},
+	}.Exit()
+}
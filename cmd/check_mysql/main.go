@@ -0,0 +1,110 @@
+package main
+
+// Checks a MySQL server accepts connections, a query returns a value in range,
+// or a replica is running and not lagging
+
+import (
+	"database/sql"
+	"flag"
+	"fmt"
+	"strconv"
+
+	"github.com/desylva/nagios/internal/nagios"
+	"github.com/desylva/nagios/internal/sqlcheck"
+	"github.com/go-sql-driver/mysql"
+)
+
+func main() {
+	dsn := flag.String("dsn", "", "connection string as user:pass@tcp(host:3306)/db, "+
+		"better given in $CHECK_MYSQL_DSN to keep the password off the command line")
+	replication := flag.Bool("replication", false, "check the replica threads are running, with -w and -c on Seconds_Behind_Master")
+	opts := sqlcheck.AddFlags("")
+	nagios.ParseFlags()
+
+	config, err := mysql.ParseDSN(*dsn)
+	if err != nil {
+		nagios.Usage("Invalid --dsn: %v", err)
+	}
+	db, err := sql.Open("mysql", *dsn)
+	if err != nil {
+		nagios.Usage("Invalid --dsn: %v", err)
+	}
+	defer db.Close()
+	server := "MySQL " + config.Addr
+
+	if *replication {
+		checkReplication(opts, db, server).Exit()
+	}
+	opts.Check(db, server).Exit()
+}
+
+// checkReplication checks the replica threads from SHOW SLAVE STATUS and
+// applies the thresholds to the replication lag
+func checkReplication(opts *sqlcheck.Options, db *sql.DB, server string) nagios.Result {
+	ctx, cancel := opts.Context()
+	defer cancel()
+	if err := opts.Connect(ctx, db); err != nil {
+		return opts.Failure(err, "connecting to "+server)
+	}
+
+	rows, err := db.QueryContext(ctx, "SHOW SLAVE STATUS")
+	if err != nil {
+		return opts.Failure(err, "querying replica status of "+server)
+	}
+	defer rows.Close()
+	columns, err := rows.Columns()
+	if err != nil {
+		return opts.Failure(err, "querying replica status of "+server)
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return opts.Failure(err, "querying replica status of "+server)
+		}
+		return nagios.Result{Status: nagios.Critical, Summary: server + " is not a replica"}
+	}
+	raw := make([]sql.NullString, len(columns))
+	dest := make([]interface{}, len(columns))
+	for i := range raw {
+		dest[i] = &raw[i]
+	}
+	if err := rows.Scan(dest...); err != nil {
+		return opts.Failure(err, "reading replica status of "+server)
+	}
+	status := make(map[string]sql.NullString, len(columns))
+	for i, column := range columns {
+		status[column] = raw[i]
+	}
+
+	ioRunning := status["Slave_IO_Running"].String
+	sqlRunning := status["Slave_SQL_Running"].String
+	if ioRunning != "Yes" || sqlRunning != "Yes" {
+		summary := fmt.Sprintf("Replication on %v is broken: IO thread %v, SQL thread %v", server, ioRunning, sqlRunning)
+		for _, column := range []string{"Last_IO_Error", "Last_SQL_Error"} {
+			if e := status[column].String; e != "" {
+				summary += " . " + e
+			}
+		}
+		return nagios.Result{Status: nagios.Critical, Summary: summary}
+	}
+
+	lag := status["Seconds_Behind_Master"]
+	seconds, err := strconv.ParseFloat(lag.String, 64)
+	if !lag.Valid || err != nil {
+		return nagios.Result{
+			Status:  nagios.Critical,
+			Summary: fmt.Sprintf("Replication on %v reports no lag, Seconds_Behind_Master is %q", server, lag.String),
+		}
+	}
+	return nagios.Result{
+		Status:  nagios.Compare(seconds, opts.Warn.Range, opts.Crit.Range),
+		Summary: fmt.Sprintf("Replication on %v is running, %vs behind the source", server, seconds),
+		Perfdata: []nagios.Perfdata{{
+			Label: "lag",
+			Value: seconds,
+			UOM:   "s",
+			Warn:  opts.Warn.String(),
+			Crit:  opts.Crit.String(),
+			Min:   "0",
+		}},
+	}
+}
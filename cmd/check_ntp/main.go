@@ -0,0 +1,144 @@
+package main
+
+// Checks the offset of the local clock from an NTP server
+
+import (
+	"encoding/binary"
+	"flag"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/desylva/nagios/internal/nagios"
+)
+
+// ntpEpoch is the start of NTP time, 1900-01-01, in Unix seconds
+const ntpEpoch = 2208988800
+
+// toNTP converts a time to a 64-bit NTP timestamp
+func toNTP(t time.Time) uint64 {
+	secs := uint64(t.Unix() + ntpEpoch)
+	frac := uint64(t.Nanosecond()) << 32 / 1e9
+	return secs<<32 | frac
+}
+
+// fromNTP converts a 64-bit NTP timestamp to a time
+func fromNTP(ts uint64) time.Time {
+	secs := int64(ts>>32) - ntpEpoch
+	nanos := int64((ts & 0xffffffff) * 1e9 >> 32)
+	return time.Unix(secs, nanos)
+}
+
+// symmetric formats a threshold on the absolute offset as a range like -0.5:0.5
+func symmetric(limit float64) string {
+	s := strconv.FormatFloat(limit, 'f', -1, 64)
+	return "-" + s + ":" + s
+}
+
+func main() {
+	warn := flag.Float64("w", 0.5, "warning when the clock offset exceeds this many seconds")
+	crit := flag.Float64("c", 1.0, "critical when the clock offset exceeds this many seconds")
+	timeout := nagios.DurationFlag{Duration: 10 * time.Second}
+	flag.Var(&timeout, "t", "query timeout, as a duration or in seconds")
+	flag.Var(&timeout, "timeout", "query timeout, as a duration or in seconds")
+	nagios.ParseFlags()
+
+	args := flag.Args()
+	if len(args) < 1 {
+		nagios.Usage("Expects 'server' and optional 'port' as first arguments")
+	}
+	port := "123"
+	if len(args) > 1 {
+		port = args[1]
+	}
+	address := net.JoinHostPort(args[0], port)
+
+	conn, err := net.DialTimeout("udp", address, timeout.Duration)
+	if err != nil {
+		nagios.Result{Status: nagios.Critical, Summary: err.Error()}.Exit()
+	}
+	defer conn.Close()
+
+	// SNTP client request: no leap indicator, version 4, mode 3 (client)
+	request := make([]byte, 48)
+	request[0] = 0<<6 | 4<<3 | 3
+	sent := time.Now()
+	binary.BigEndian.PutUint64(request[40:], toNTP(sent))
+	conn.SetDeadline(sent.Add(timeout.Duration))
+	response := make([]byte, 48)
+	_, err = conn.Write(request)
+	var n int
+	if err == nil {
+		n, err = conn.Read(response)
+	}
+	received := time.Now()
+	if err, ok := err.(net.Error); ok && err.Timeout() {
+		nagios.Result{
+			Status:  nagios.Critical,
+			Summary: fmt.Sprintf("No response from NTP server %v after %v", address, timeout.Duration),
+		}.Exit()
+	}
+	if err != nil {
+		nagios.Result{Status: nagios.Critical, Summary: fmt.Sprintf("NTP server %v unreachable: %v", address, err)}.Exit()
+	}
+	if n < 48 || response[0]&0x7 != 4 {
+		nagios.Result{Status: nagios.Unknown, Summary: fmt.Sprintf("Invalid NTP response from %v", address)}.Exit()
+	}
+	if binary.BigEndian.Uint64(response[24:]) != binary.BigEndian.Uint64(request[40:]) {
+		nagios.Result{Status: nagios.Unknown, Summary: fmt.Sprintf("NTP response from %v does not answer our request", address)}.Exit()
+	}
+
+	stratum := int(response[1])
+	if stratum == 0 {
+		// Kiss-of-death: the reference id holds an ASCII code like RATE or DENY
+		code := strings.TrimRight(string(response[12:16]), "\x00 ")
+		nagios.Result{
+			Status:  nagios.Unknown,
+			Summary: fmt.Sprintf("NTP server %v sent kiss-of-death %v, refusing to serve the time", address, code),
+		}.Exit()
+	}
+	if response[0]>>6 == 3 {
+		nagios.Result{
+			Status:  nagios.Critical,
+			Summary: fmt.Sprintf("NTP server %v is not synchronized (stratum %v)", address, stratum),
+		}.Exit()
+	}
+
+	serverReceived := fromNTP(binary.BigEndian.Uint64(response[32:]))
+	serverSent := fromNTP(binary.BigEndian.Uint64(response[40:]))
+	offset := (serverReceived.Sub(sent) + serverSent.Sub(received)) / 2
+	delay := received.Sub(sent) - serverSent.Sub(serverReceived)
+	seconds := offset.Round(time.Microsecond).Seconds()
+
+	status := nagios.OK
+	switch {
+	case math.Abs(seconds) > *crit:
+		status = nagios.Critical
+	case math.Abs(seconds) > *warn:
+		status = nagios.Warning
+	}
+
+	nagios.Result{
+		Status:  status,
+		Summary: fmt.Sprintf("Offset %vs from NTP server %v, stratum %v", seconds, address, stratum),
+		Perfdata: []nagios.Perfdata{{
+			Label: "offset",
+			Value: seconds,
+			UOM:   "s",
+			Warn:  symmetric(*warn),
+			Crit:  symmetric(*crit),
+		}, {
+			Label: "delay",
+			Value: delay.Round(time.Microsecond).Seconds(),
+			UOM:   "s",
+			Min:   "0",
+		}, {
+			Label: "stratum",
+			Value: float64(stratum),
+			Min:   "0",
+		}},
+	}.Exit()
+}
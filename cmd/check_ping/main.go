@@ -0,0 +1,208 @@
+package main
+
+// Checks a host answers ICMP echo requests, measuring round trip time and packet loss
+
+import (
+	"flag"
+	"fmt"
+	"math"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/desylva/nagios/internal/nagios"
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+)
+
+// threshold is a check_ping style "rta,pl%" limit
+type threshold struct {
+	rta float64
+	pl  float64
+}
+
+func (t *threshold) String() string {
+	return fmt.Sprintf("%v,%v%%", t.rta, t.pl)
+}
+
+// Set parses a "100.0,20%" round trip time in milliseconds and packet loss
+func (t *threshold) Set(s string) error {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 || !strings.HasSuffix(parts[1], "%") {
+		return fmt.Errorf("threshold %q is not in the form 'rta,pl%%'", s)
+	}
+	rta, err := strconv.ParseFloat(parts[0], 64)
+	if err != nil {
+		return fmt.Errorf("invalid round trip time in %q: %v", s, err)
+	}
+	pl, err := strconv.ParseFloat(strings.TrimSuffix(parts[1], "%"), 64)
+	if err != nil {
+		return fmt.Errorf("invalid packet loss in %q: %v", s, err)
+	}
+	t.rta, t.pl = rta, pl
+	return nil
+}
+
+// breached reports whether the round trip time or the packet loss reach the limit
+func (t *threshold) breached(rta, pl float64) bool {
+	return rta >= t.rta || pl >= t.pl
+}
+
+func main() {
+	warn := threshold{rta: 100, pl: 20}
+	crit := threshold{rta: 500, pl: 60}
+	flag.Var(&warn, "w", "warning threshold as 'rta,pl%' with the round trip time in milliseconds")
+	flag.Var(&crit, "c", "critical threshold as 'rta,pl%' with the round trip time in milliseconds")
+	count := flag.Int("n", 5, "number of echo requests to send")
+	interval := nagios.DurationFlag{Duration: 200 * time.Millisecond}
+	flag.Var(&interval, "i", "delay between echo requests, as a duration or in seconds")
+	timeout := nagios.DurationFlag{Duration: 10 * time.Second}
+	flag.Var(&timeout, "t", "overall timeout, as a duration or in seconds")
+	flag.Var(&timeout, "timeout", "overall timeout, as a duration or in seconds")
+	nagios.ParseFlags()
+
+	args := flag.Args()
+	if len(args) < 1 {
+		nagios.Usage("Expects 'host' as first argument")
+	}
+	host := args[0]
+	if *count < 1 {
+		nagios.Usage("Expects -n to be at least 1")
+	}
+
+	addr, err := net.ResolveIPAddr("ip", host)
+	if err != nil {
+		nagios.Result{Status: nagios.Critical, Summary: err.Error()}.Exit()
+	}
+
+	conn, privileged, err := listen(addr.IP.To4() == nil)
+	if err != nil {
+		nagios.Result{
+			Status:  nagios.Unknown,
+			Summary: fmt.Sprintf("cannot open an ICMP socket, run as root or allow unprivileged ping with net.ipv4.ping_group_range: %v", err),
+		}.Exit()
+	}
+
+	var peer net.Addr = addr
+	protocol := 1
+	var echoType, replyType icmp.Type = ipv4.ICMPTypeEcho, ipv4.ICMPTypeEchoReply
+	if addr.IP.To4() == nil {
+		protocol = 58
+		echoType, replyType = ipv6.ICMPTypeEchoRequest, ipv6.ICMPTypeEchoReply
+	}
+	if !privileged {
+		peer = &net.UDPAddr{IP: addr.IP, Zone: addr.Zone}
+	}
+
+	// Unprivileged sockets have the kernel rewrite the echo id, so replies
+	// are only matched on it with raw sockets
+	id := os.Getpid() & 0xffff
+	deadline := time.Now().Add(timeout.Duration)
+	var rtts []time.Duration
+	buf := make([]byte, 1500)
+
+	for seq := 0; seq < *count && time.Now().Before(deadline); seq++ {
+		if seq > 0 {
+			time.Sleep(interval.Duration)
+		}
+		msg := icmp.Message{
+			Type: echoType,
+			Body: &icmp.Echo{ID: id, Seq: seq, Data: []byte("check_ping")},
+		}
+		packet, err := msg.Marshal(nil)
+		if err != nil {
+			nagios.Result{Status: nagios.Unknown, Summary: err.Error()}.Exit()
+		}
+		sent := time.Now()
+		if _, err := conn.WriteTo(packet, peer); err != nil {
+			nagios.Result{Status: nagios.Critical, Summary: err.Error()}.Exit()
+		}
+
+		wait := sent.Add(time.Second)
+		if wait.After(deadline) {
+			wait = deadline
+		}
+		conn.SetReadDeadline(wait)
+		for {
+			n, _, err := conn.ReadFrom(buf)
+			if err != nil {
+				break
+			}
+			reply, err := icmp.ParseMessage(protocol, buf[:n])
+			if err != nil || reply.Type != replyType {
+				continue
+			}
+			echo, ok := reply.Body.(*icmp.Echo)
+			if !ok || echo.Seq != seq || (privileged && echo.ID != id) {
+				continue
+			}
+			rtts = append(rtts, time.Since(sent))
+			break
+		}
+	}
+
+	sent := *count
+	pl := float64(sent-len(rtts)) * 100 / float64(sent)
+	var rta float64
+	for _, rtt := range rtts {
+		rta += float64(rtt) / float64(time.Millisecond)
+	}
+	if len(rtts) > 0 {
+		rta /= float64(len(rtts))
+	}
+	rta = math.Round(rta*1000) / 1000
+
+	status := nagios.OK
+	switch {
+	case len(rtts) == 0 || crit.breached(rta, pl):
+		status = nagios.Critical
+	case warn.breached(rta, pl):
+		status = nagios.Warning
+	}
+
+	summary := fmt.Sprintf("%v Packet loss = %v%%, RTA = %v ms", host, pl, rta)
+	if len(rtts) == 0 {
+		summary = fmt.Sprintf("%v Packet loss = 100%%", host)
+	}
+	nagios.Result{
+		Status:  status,
+		Summary: summary,
+		Perfdata: []nagios.Perfdata{{
+			Label: "rta",
+			Value: rta,
+			UOM:   "ms",
+			Warn:  strconv.FormatFloat(warn.rta, 'f', -1, 64),
+			Crit:  strconv.FormatFloat(crit.rta, 'f', -1, 64),
+			Min:   "0",
+		}, {
+			Label: "pl",
+			Value: pl,
+			UOM:   "%",
+			Warn:  strconv.FormatFloat(warn.pl, 'f', -1, 64),
+			Crit:  strconv.FormatFloat(crit.pl, 'f', -1, 64),
+			Min:   "0",
+			Max:   "100",
+		}},
+	}.Exit()
+}
+
+// listen opens a raw ICMP socket, falling back to an unprivileged datagram
+// ICMP socket where the platform allows it
+func listen(v6 bool) (*icmp.PacketConn, bool, error) {
+	raw, dgram, address := "ip4:icmp", "udp4", "0.0.0.0"
+	if v6 {
+		raw, dgram, address = "ip6:ipv6-icmp", "udp6", "::"
+	}
+	conn, err := icmp.ListenPacket(raw, address)
+	if err == nil {
+		return conn, true, nil
+	}
+	conn, dgramErr := icmp.ListenPacket(dgram, address)
+	if dgramErr == nil {
+		return conn, false, nil
+	}
+	return nil, false, err
+}
@@ -0,0 +1,261 @@
+package main
+
+// Checks a Redis server answers PING, optionally checking its memory and clients
+
+import (
+	"bufio"
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/desylva/nagios/internal/nagios"
+	"github.com/desylva/nagios/internal/netcheck"
+)
+
+// sizeRange is a range flag on a size, whose ends may have a K, M, G or T
+// suffix like "1G:". The range is kept in bytes for perfdata.
+type sizeRange struct {
+	nagios.RangeFlag
+}
+
+// Set parses s as a Nagios range spec of sizes
+func (r *sizeRange) Set(s string) error {
+	spec := strings.TrimPrefix(s, "@")
+	ends := strings.SplitN(spec, ":", 2)
+	for i, end := range ends {
+		if end == "" || end == "~" {
+			continue
+		}
+		v, err := nagios.ParseSize(end)
+		if err != nil {
+			return err
+		}
+		ends[i] = strconv.FormatFloat(v, 'f', -1, 64)
+	}
+	return r.RangeFlag.Set(s[:len(s)-len(spec)] + strings.Join(ends, ":"))
+}
+
+// thresholds is a "memory,clients" -w or -c threshold setting the memory
+// and clients ranges at once, where either may be left empty like "1G,"
+type thresholds struct {
+	memory  *sizeRange
+	clients *nagios.RangeFlag
+}
+
+func (t *thresholds) String() string {
+	if t.memory == nil || (t.memory.Range == nil && t.clients.Range == nil) {
+		return ""
+	}
+	return t.memory.String() + "," + t.clients.String()
+}
+
+// Set parses a "1G,500" used memory and connected clients pair of ranges
+func (t *thresholds) Set(s string) error {
+	parts := strings.Split(s, ",")
+	if len(parts) != 2 {
+		return fmt.Errorf("threshold %q is not in the form 'memory,clients'", s)
+	}
+	if parts[0] != "" {
+		if err := t.memory.Set(parts[0]); err != nil {
+			return fmt.Errorf("invalid used memory in %q: %v", s, err)
+		}
+	}
+	if parts[1] != "" {
+		if err := t.clients.Set(parts[1]); err != nil {
+			return fmt.Errorf("invalid connected clients in %q: %v", s, err)
+		}
+	}
+	return nil
+}
+
+// redisError is an error reply from the server, like "NOAUTH Authentication required."
+type redisError string
+
+func (e redisError) Error() string {
+	return string(e)
+}
+
+// Reply returns the error reply for netcheck.Failure
+func (e redisError) Reply() string {
+	return string(e)
+}
+
+// command sends a command and reads its reply, which must be a simple
+// string, an integer or a bulk string
+func command(rw *bufio.ReadWriter, args ...string) (string, error) {
+	fmt.Fprintf(rw, "*%d\r\n", len(args))
+	for _, arg := range args {
+		fmt.Fprintf(rw, "$%d\r\n%s\r\n", len(arg), arg)
+	}
+	if err := rw.Flush(); err != nil {
+		return "", err
+	}
+
+	line, err := rw.ReadString('\n')
+	if err != nil {
+		return "", err
+	}
+	line = strings.TrimRight(line, "\r\n")
+	if line == "" {
+		return "", errors.New("empty reply")
+	}
+	switch line[0] {
+	case '+', ':':
+		return line[1:], nil
+	case '-':
+		return "", redisError(line[1:])
+	case '$':
+		size, err := strconv.Atoi(line[1:])
+		if err != nil || size < 0 {
+			return "", fmt.Errorf("invalid bulk reply %q", line)
+		}
+		data := make([]byte, size+2)
+		if _, err := io.ReadFull(rw, data); err != nil {
+			return "", err
+		}
+		return string(data[:size]), nil
+	}
+	return "", fmt.Errorf("unexpected reply %q", line)
+}
+
+// parseInfo reads the "name:value" lines of an INFO reply
+func parseInfo(info string) map[string]string {
+	values := make(map[string]string)
+	for _, line := range strings.Split(info, "\n") {
+		line = strings.TrimSpace(line)
+		if i := strings.Index(line, ":"); i > 0 && !strings.HasPrefix(line, "#") {
+			values[line[:i]] = line[i+1:]
+		}
+	}
+	return values
+}
+
+func main() {
+	port := flag.Int("p", 6379, "port to connect to")
+	auth := flag.String("auth", "", "password sent with AUTH")
+	db := flag.Int("db", 0, "database selected with SELECT")
+	useTLS := flag.Bool("tls", false, "connect over TLS")
+	var insecure bool
+	flag.BoolVar(&insecure, "k", false, "skip TLS certificate verification")
+	flag.BoolVar(&insecure, "insecure", false, "skip TLS certificate verification")
+	var warnMemory, critMemory sizeRange
+	flag.Var(&warnMemory, "warn-memory", "warning threshold on used_memory, as a range of sizes like 1G")
+	flag.Var(&critMemory, "crit-memory", "critical threshold on used_memory, as a range of sizes like 2G")
+	var warnClients, critClients nagios.RangeFlag
+	flag.Var(&warnClients, "warn-clients", "warning threshold on connected_clients")
+	flag.Var(&critClients, "crit-clients", "critical threshold on connected_clients")
+	flag.Var(&thresholds{&warnMemory, &warnClients}, "w", "warning thresholds as 'memory,clients', like 1G,500 or 1G, for --warn-memory and --warn-clients")
+	flag.Var(&thresholds{&critMemory, &critClients}, "c", "critical thresholds as 'memory,clients', like 2G,1000 or ,1000, for --crit-memory and --crit-clients")
+	timeout := nagios.DurationFlag{Duration: 10 * time.Second}
+	flag.Var(&timeout, "t", "connect and command timeout, as a duration or in seconds")
+	flag.Var(&timeout, "timeout", "connect and command timeout, as a duration or in seconds")
+	family := netcheck.AddFlags()
+	nagios.ParseFlags()
+	family.Validate()
+
+	args := flag.Args()
+	if len(args) < 1 {
+		nagios.Usage("Expects 'host' as first argument")
+	}
+	host := args[0]
+	address := net.JoinHostPort(host, strconv.Itoa(*port))
+
+	start := time.Now()
+	conn, err := family.Dial(address, timeout.Duration)
+	if err != nil {
+		netcheck.Failure(err, "connect", timeout.Duration, address).Exit()
+	}
+	conn.SetDeadline(start.Add(timeout.Duration))
+	if *useTLS {
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: host, InsecureSkipVerify: insecure})
+		if err := tlsConn.Handshake(); err != nil {
+			netcheck.Failure(err, "TLS handshake", timeout.Duration, address).Exit()
+		}
+		conn = tlsConn
+	}
+	defer conn.Close()
+	rw := bufio.NewReadWriter(bufio.NewReader(conn), bufio.NewWriter(conn))
+
+	if *auth != "" {
+		if _, err := command(rw, "AUTH", *auth); err != nil {
+			netcheck.Failure(err, "AUTH", timeout.Duration, address).Exit()
+		}
+	}
+	if *db != 0 {
+		if _, err := command(rw, "SELECT", strconv.Itoa(*db)); err != nil {
+			netcheck.Failure(err, "SELECT", timeout.Duration, address).Exit()
+		}
+	}
+
+	pingStart := time.Now()
+	pong, err := command(rw, "PING")
+	latency := time.Since(pingStart).Round(time.Microsecond).Seconds()
+	if err != nil {
+		netcheck.Failure(err, "PING", timeout.Duration, address).Exit()
+	}
+	if pong != "PONG" {
+		nagios.Result{
+			Status:  nagios.Critical,
+			Summary: fmt.Sprintf("%v . Expected reply: PONG . Returns reply %v to PING !", address, pong),
+		}.Exit()
+	}
+
+	summary := fmt.Sprintf("%v answers PING in %vs", address, latency)
+	status := nagios.OK
+	perfdata := []nagios.Perfdata{{
+		Label: "latency",
+		Value: latency,
+		UOM:   "s",
+		Min:   "0",
+	}}
+
+	checkMemory := warnMemory.Range != nil || critMemory.Range != nil
+	checkClients := warnClients.Range != nil || critClients.Range != nil
+	if checkMemory || checkClients {
+		reply, err := command(rw, "INFO")
+		if err != nil {
+			netcheck.Failure(err, "INFO", timeout.Duration, address).Exit()
+		}
+		info := parseInfo(reply)
+		metric := func(name string, warn, crit *nagios.RangeFlag, uom string) {
+			value, err := strconv.ParseFloat(info[name], 64)
+			if err != nil {
+				status = nagios.Worst(status, nagios.Unknown)
+				summary += fmt.Sprintf(" . INFO has no %v", name)
+				return
+			}
+			summary += fmt.Sprintf(" . %v %v", name, strconv.FormatFloat(value, 'f', -1, 64))
+			switch metricStatus := nagios.Compare(value, warn.Range, crit.Range); metricStatus {
+			case nagios.Critical:
+				summary += fmt.Sprintf(" (crit %v)", crit)
+				status = nagios.Worst(status, metricStatus)
+			case nagios.Warning:
+				summary += fmt.Sprintf(" (warn %v)", warn)
+				status = nagios.Worst(status, metricStatus)
+			}
+			perfdata = append(perfdata, nagios.Perfdata{
+				Label: name,
+				Value: value,
+				UOM:   uom,
+				Warn:  warn.String(),
+				Crit:  crit.String(),
+				Min:   "0",
+			})
+		}
+		if checkMemory {
+			metric("used_memory", &warnMemory.RangeFlag, &critMemory.RangeFlag, "B")
+		}
+		if checkClients {
+			metric("connected_clients", &warnClients, &critClients, "")
+		}
+	}
+	command(rw, "QUIT")
+
+	nagios.Result{Status: status, Summary: summary, Perfdata: perfdata}.Exit()
+}
@@ -0,0 +1,101 @@
+package main
+
+// Checks a mail server answers the SMTP handshake, optionally upgrading with STARTTLS
+
+import (
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"net"
+	"net/textproto"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/desylva/nagios/internal/nagios"
+	"github.com/desylva/nagios/internal/netcheck"
+)
+
+func main() {
+	port := flag.Int("p", 25, "port to connect to")
+	startTLS := flag.Bool("starttls", false, "upgrade the session with STARTTLS after EHLO")
+	expectGreeting := flag.String("expect-greeting", "", "substring expected in the 220 greeting")
+	var warn, crit nagios.RangeFlag
+	flag.Var(&warn, "w", "warning threshold on connect and handshake time in seconds")
+	flag.Var(&crit, "c", "critical threshold on connect and handshake time in seconds")
+	timeout := nagios.DurationFlag{Duration: 10 * time.Second}
+	flag.Var(&timeout, "t", "connect and handshake timeout, as a duration or in seconds")
+	flag.Var(&timeout, "timeout", "connect and handshake timeout, as a duration or in seconds")
+	family := netcheck.AddFlags()
+	nagios.ParseFlags()
+	family.Validate()
+
+	args := flag.Args()
+	if len(args) < 1 {
+		nagios.Usage("Expects 'host' as first argument")
+	}
+	host := args[0]
+	address := net.JoinHostPort(host, strconv.Itoa(*port))
+
+	start := time.Now()
+	conn, err := family.Dial(address, timeout.Duration)
+	if err != nil {
+		netcheck.Failure(err, "connect", timeout.Duration, address).Exit()
+	}
+	conn.SetDeadline(start.Add(timeout.Duration))
+	text := textproto.NewConn(conn)
+
+	_, greeting, err := text.ReadResponse(220)
+	if err != nil {
+		netcheck.Failure(err, "greeting", timeout.Duration, address).Exit()
+	}
+	if !strings.Contains(greeting, *expectGreeting) {
+		nagios.Result{
+			Status:  nagios.Critical,
+			Summary: fmt.Sprintf("%v . Expected greeting: %v . Returns greeting %v !", address, *expectGreeting, greeting),
+		}.Exit()
+	}
+
+	localName, err := os.Hostname()
+	if err != nil {
+		localName = "localhost"
+	}
+	if _, _, err := netcheck.Command(text, 250, "EHLO %s", localName); err != nil {
+		netcheck.Failure(err, "EHLO", timeout.Duration, address).Exit()
+	}
+
+	if *startTLS {
+		if _, _, err := netcheck.Command(text, 220, "STARTTLS"); err != nil {
+			netcheck.Failure(err, "STARTTLS", timeout.Duration, address).Exit()
+		}
+		tlsConn := tls.Client(conn, &tls.Config{ServerName: host})
+		if err := tlsConn.Handshake(); err != nil {
+			netcheck.Failure(err, "TLS handshake", timeout.Duration, address).Exit()
+		}
+		text = textproto.NewConn(tlsConn)
+		if _, _, err := netcheck.Command(text, 250, "EHLO %s", localName); err != nil {
+			netcheck.Failure(err, "EHLO after STARTTLS", timeout.Duration, address).Exit()
+		}
+	}
+	elapsed := time.Since(start).Round(time.Millisecond).Seconds()
+	netcheck.Command(text, 221, "QUIT")
+	text.Close()
+
+	summary := fmt.Sprintf("%v greets %v", address, netcheck.FirstLine(greeting))
+	if *startTLS {
+		summary += " . STARTTLS ok"
+	}
+	nagios.Result{
+		Status:  nagios.Compare(elapsed, warn.Range, crit.Range),
+		Summary: summary,
+		Perfdata: []nagios.Perfdata{{
+			Label: "time",
+			Value: elapsed,
+			UOM:   "s",
+			Warn:  warn.String(),
+			Crit:  crit.String(),
+			Min:   "0",
+		}},
+	}.Exit()
+}
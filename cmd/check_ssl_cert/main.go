@@ -0,0 +1,113 @@
+package main
+
+// Checks the TLS certificate served by a host is valid and not close to expiry
+
+import (
+	"crypto/tls"
+	"flag"
+	"fmt"
+	"net"
+	"strconv"
+	"time"
+
+	"github.com/desylva/nagios/internal/nagios"
+	"github.com/desylva/nagios/internal/netcheck"
+)
+
+func main() {
+	warnDays := flag.Int("w", 30, "warning when the certificate expires in less than this many days")
+	critDays := flag.Int("c", 14, "critical when the certificate expires in less than this many days")
+	timeout := nagios.DurationFlag{Duration: 10 * time.Second}
+	flag.Var(&timeout, "t", "connect timeout, as a duration or in seconds")
+	flag.Var(&timeout, "timeout", "connect timeout, as a duration or in seconds")
+	serverName := flag.String("servername", "", "server name sent for SNI and checked against the certificate (default: host)")
+	noCheckHostname := flag.Bool("no-check-hostname", false, "do not check the certificate matches the server name")
+	family := netcheck.AddFlags()
+	nagios.ParseFlags()
+	family.Validate()
+
+	args := flag.Args()
+	if len(args) < 1 {
+		nagios.Usage("Expects 'host' and optional 'port' as first arguments")
+	}
+	host := args[0]
+	port := "443"
+	if len(args) > 1 {
+		port = args[1]
+	}
+	address := net.JoinHostPort(host, port)
+	if *serverName == "" {
+		*serverName = host
+	}
+
+	start := time.Now()
+	rawConn, err := family.Dial(address, timeout.Duration)
+	var conn *tls.Conn
+	if err == nil {
+		// Verification is done below so expired certificates can be reported
+		conn = tls.Client(rawConn, &tls.Config{
+			ServerName:         *serverName,
+			InsecureSkipVerify: true,
+		})
+		conn.SetDeadline(start.Add(timeout.Duration))
+		err = conn.Handshake()
+	}
+	if err, ok := err.(net.Error); ok && err.Timeout() {
+		nagios.Result{
+			Status:  nagios.Critical,
+			Summary: fmt.Sprintf("timed out after %v connecting to %v", timeout.Duration, address),
+		}.Exit()
+	}
+	if err != nil {
+		nagios.Result{Status: nagios.Critical, Summary: err.Error()}.Exit()
+	}
+	conn.Close()
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		nagios.Result{Status: nagios.Critical, Summary: "No certificate presented by " + address}.Exit()
+	}
+	leaf := certs[0]
+	subject := leaf.Subject.CommonName
+	notAfter := leaf.NotAfter.UTC().Format("2006-01-02 15:04:05 MST")
+
+	now := time.Now()
+	if now.Before(leaf.NotBefore) {
+		nagios.Result{
+			Status:  nagios.Critical,
+			Summary: fmt.Sprintf("Certificate %v is not valid before %v", subject, leaf.NotBefore.UTC().Format("2006-01-02 15:04:05 MST")),
+		}.Exit()
+	}
+	if now.After(leaf.NotAfter) {
+		nagios.Result{
+			Status:  nagios.Critical,
+			Summary: fmt.Sprintf("Certificate %v expired on %v", subject, notAfter),
+		}.Exit()
+	}
+	if !*noCheckHostname {
+		if err := leaf.VerifyHostname(*serverName); err != nil {
+			nagios.Result{Status: nagios.Critical, Summary: err.Error()}.Exit()
+		}
+	}
+
+	days := int(leaf.NotAfter.Sub(now).Hours() / 24)
+	perfdata := []nagios.Perfdata{{
+		Label: "days",
+		Value: float64(days),
+		Warn:  strconv.Itoa(*warnDays),
+		Crit:  strconv.Itoa(*critDays),
+	}}
+
+	status := nagios.OK
+	switch {
+	case days < *critDays:
+		status = nagios.Critical
+	case days < *warnDays:
+		status = nagios.Warning
+	}
+	nagios.Result{
+		Status:   status,
+		Summary:  fmt.Sprintf("Certificate %v expires in %v days (%v)", subject, days, notAfter),
+		Perfdata: perfdata,
+	}.Exit()
+}
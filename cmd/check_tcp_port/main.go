@@ -0,0 +1,82 @@
+package main
+
+// Checks a TCP port accepts connections, optionally sending an expected banner
+
+import (
+	"bufio"
+	"flag"
+	"fmt"
+	"net"
+	"strings"
+	"time"
+
+	"github.com/desylva/nagios/internal/nagios"
+	"github.com/desylva/nagios/internal/netcheck"
+)
+
+func main() {
+	var warn, crit nagios.RangeFlag
+	flag.Var(&warn, "w", "warning threshold on connect time in seconds")
+	flag.Var(&crit, "c", "critical threshold on connect time in seconds")
+	timeout := nagios.DurationFlag{Duration: 10 * time.Second}
+	flag.Var(&timeout, "t", "connect timeout, as a duration or in seconds")
+	flag.Var(&timeout, "timeout", "connect timeout, as a duration or in seconds")
+	expect := flag.String("expect", "", "substring expected in the first line sent by the server")
+	family := netcheck.AddFlags()
+	nagios.ParseFlags()
+	family.Validate()
+
+	args := flag.Args()
+	if len(args) < 2 {
+		nagios.Usage("Expects 'host' and 'port' as first arguments")
+	}
+	address := net.JoinHostPort(args[0], args[1])
+
+	start := time.Now()
+	conn, err := family.Dial(address, timeout.Duration)
+	elapsed := time.Since(start).Round(time.Millisecond).Seconds()
+	if err, ok := err.(net.Error); ok && err.Timeout() {
+		nagios.Result{
+			Status:  nagios.Critical,
+			Summary: fmt.Sprintf("timed out after %v connecting to %v", timeout.Duration, address),
+		}.Exit()
+	}
+	if err != nil {
+		nagios.Result{Status: nagios.Critical, Summary: err.Error()}.Exit()
+	}
+
+	perfdata := []nagios.Perfdata{{
+		Label: "time",
+		Value: elapsed,
+		UOM:   "s",
+		Warn:  warn.String(),
+		Crit:  crit.String(),
+		Min:   "0",
+	}}
+
+	summary := fmt.Sprintf("Connected to %v in %vs", address, elapsed)
+
+	if *expect != "" {
+		conn.SetReadDeadline(start.Add(timeout.Duration))
+		banner, err := bufio.NewReader(conn).ReadString('\n')
+		banner = strings.TrimSpace(banner)
+		if err != nil && banner == "" {
+			nagios.Result{
+				Status:   nagios.Critical,
+				Summary:  fmt.Sprintf("Connected to %v but no banner received: %v", address, err),
+				Perfdata: perfdata,
+			}.Exit()
+		}
+		if !strings.Contains(banner, *expect) {
+			nagios.Result{
+				Status:   nagios.Critical,
+				Summary:  fmt.Sprintf("Connected to %v . Expected banner: %v . Returns banner %v !", address, *expect, banner),
+				Perfdata: perfdata,
+			}.Exit()
+		}
+		summary += " . Banner " + banner
+	}
+
+	status := nagios.Compare(elapsed, warn.Range, crit.Range)
+	nagios.Result{Status: status, Summary: summary, Perfdata: perfdata}.Exit()
+}
@@ -0,0 +1,10 @@
+module github.com/desylva/nagios
+
+go 1.21
+
+require (
+	github.com/go-sql-driver/mysql v1.7.1
+	github.com/lib/pq v1.10.9
+	golang.org/x/net v0.34.0
+	golang.org/x/sys v0.29.0
+)
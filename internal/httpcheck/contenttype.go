@@ -0,0 +1,72 @@
+package httpcheck
+
+import (
+	"flag"
+	"fmt"
+	"mime"
+	"net/http"
+	"strings"
+
+	"github.com/desylva/nagios/internal/nagios"
+)
+
+// ContentType holds the flags checking the Content-Type of the final response
+type ContentType struct {
+	Expect string
+	Strict bool
+}
+
+// AddContentTypeFlags registers --expect-content-type and
+// --strict-content-type on the command line
+func AddContentTypeFlags() *ContentType {
+	c := &ContentType{}
+	flag.StringVar(&c.Expect, "expect-content-type", "", "expected Content-Type of the final response, like application/json")
+	flag.BoolVar(&c.Strict, "strict-content-type", false, "also compare the Content-Type parameters, like charset")
+	return c
+}
+
+// Mismatch reports a response whose Content-Type differs from
+// --expect-content-type as CRITICAL. Parameters such as charset are
+// ignored unless --strict-content-type is given.
+func (c *ContentType) Mismatch(resp *http.Response) (nagios.Result, bool) {
+	if c.Expect == "" || c.matches(resp.Header.Get("Content-Type")) {
+		return nagios.Result{}, false
+	}
+	return nagios.Result{
+		Status: nagios.Critical,
+		Summary: fmt.Sprintf("Returns url %v with Content-Type %q . Expected Content-Type %v !",
+			resp.Request.URL, resp.Header.Get("Content-Type"), c.Expect),
+	}, true
+}
+
+// Note returns the remark appended to the summary echoing the Content-Type
+// when one is expected
+func (c *ContentType) Note(resp *http.Response) string {
+	if c.Expect == "" {
+		return ""
+	}
+	return " . Content-Type " + resp.Header.Get("Content-Type")
+}
+
+func (c *ContentType) matches(actual string) bool {
+	expectType, expectParams, err := mime.ParseMediaType(c.Expect)
+	if err != nil {
+		return strings.EqualFold(strings.TrimSpace(actual), strings.TrimSpace(c.Expect))
+	}
+	actualType, actualParams, err := mime.ParseMediaType(actual)
+	if err != nil || actualType != expectType {
+		return false
+	}
+	if !c.Strict {
+		return true
+	}
+	if len(actualParams) != len(expectParams) {
+		return false
+	}
+	for name, value := range expectParams {
+		if !strings.EqualFold(actualParams[name], value) {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,28 @@
+package httpcheck
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Cookies is a repeatable flag.Value of "name=value" request cookies
+type Cookies []*http.Cookie
+
+func (c *Cookies) String() string {
+	fields := make([]string, len(*c))
+	for i, cookie := range *c {
+		fields[i] = cookie.String()
+	}
+	return strings.Join(fields, "; ")
+}
+
+// Set adds a "name=value" cookie
+func (c *Cookies) Set(s string) error {
+	i := strings.Index(s, "=")
+	if i <= 0 {
+		return fmt.Errorf("cookie %q is not in the form 'name=value'", s)
+	}
+	*c = append(*c, &http.Cookie{Name: strings.TrimSpace(s[:i]), Value: strings.TrimSpace(s[i+1:])})
+	return nil
+}
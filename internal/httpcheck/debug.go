@@ -0,0 +1,72 @@
+package httpcheck
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"sort"
+	"time"
+)
+
+// debugTransport writes a trace of each request and response it carries
+type debugTransport struct {
+	next http.RoundTripper
+	w    io.Writer
+}
+
+// Debug makes client write each request line and header, and each
+// response status and header, to w. Credentials are redacted.
+func Debug(client *http.Client, w io.Writer) {
+	next := client.Transport
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	client.Transport = &debugTransport{next: next, w: w}
+}
+
+func (t *debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	fmt.Fprintf(t.w, "> %v %v\n", req.Method, req.URL)
+	host := req.Host
+	if host == "" {
+		host = req.URL.Host
+	}
+	fmt.Fprintf(t.w, "> Host: %v\n", host)
+	writeHeader(t.w, "> ", redacted(req.Header))
+
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+	elapsed := time.Since(start).Round(time.Millisecond)
+	if err != nil {
+		fmt.Fprintf(t.w, "! %v after %v\n\n", err, elapsed)
+		return nil, err
+	}
+	fmt.Fprintf(t.w, "< %v %v (%v)\n", resp.Proto, resp.Status, elapsed)
+	writeHeader(t.w, "< ", resp.Header)
+	fmt.Fprintln(t.w)
+	return resp, nil
+}
+
+// redacted returns a copy of header without the credentials
+func redacted(header http.Header) http.Header {
+	header = header.Clone()
+	for _, name := range []string{"Authorization", "Proxy-Authorization"} {
+		if header.Get(name) != "" {
+			header.Set(name, "<redacted>")
+		}
+	}
+	return header
+}
+
+// writeHeader writes each header line of header to w after prefix
+func writeHeader(w io.Writer, prefix string, header http.Header) {
+	names := make([]string, 0, len(header))
+	for name := range header {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		for _, value := range header[name] {
+			fmt.Fprintf(w, "%v%v: %v\n", prefix, name, value)
+		}
+	}
+}
@@ -0,0 +1,40 @@
+package httpcheck
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// Headers is a repeatable flag.Value of "Name: Value" request headers.
+// Headers are added in the order given and duplicates are kept.
+type Headers []string
+
+func (h *Headers) String() string {
+	return strings.Join(*h, ", ")
+}
+
+// Set adds a "Name: Value" header
+func (h *Headers) Set(s string) error {
+	i := strings.Index(s, ":")
+	if i <= 0 {
+		return fmt.Errorf("header %q is not in the form 'Name: Value'", s)
+	}
+	*h = append(*h, s)
+	return nil
+}
+
+// Apply adds the headers to the request. A Host header sets the request
+// authority, as the client ignores Host in the header map.
+func (h Headers) Apply(req *http.Request) {
+	for _, header := range h {
+		i := strings.Index(header, ":")
+		name := strings.TrimSpace(header[:i])
+		value := strings.TrimSpace(header[i+1:])
+		if http.CanonicalHeaderKey(name) == "Host" {
+			req.Host = value
+			continue
+		}
+		req.Header.Add(name, value)
+	}
+}
@@ -0,0 +1,76 @@
+package httpcheck
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestHeadersSet(t *testing.T) {
+	tests := []struct {
+		header string
+		valid  bool
+	}{
+		{"Accept: text/html", true},
+		{"X-Forwarded-For:10.0.0.1", true},
+		{"Referer: http://example.test:8080/path", true},
+		{"X-Empty:", true},
+		{"Accept text/html", false},
+		{": no name", false},
+		{"", false},
+	}
+	for _, test := range tests {
+		var h Headers
+		err := h.Set(test.header)
+		if test.valid && err != nil {
+			t.Errorf("Set(%q): %v", test.header, err)
+		}
+		if !test.valid && err == nil {
+			t.Errorf("Set(%q) accepted an invalid header", test.header)
+		}
+	}
+}
+
+func TestHeadersApply(t *testing.T) {
+	var received *http.Request
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received = r
+	}))
+	defer srv.Close()
+
+	var h Headers
+	for _, header := range []string{
+		"Host: example.test",
+		"Referer: http://example.test:8080/path",
+		"X-Token:  abc ",
+		"X-Token: def",
+	} {
+		if err := h.Set(header); err != nil {
+			t.Fatal(err)
+		}
+	}
+	req, err := http.NewRequest("GET", srv.URL, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	h.Apply(req)
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if received.Host != "example.test" {
+		t.Errorf("Host = %q, want example.test", received.Host)
+	}
+	if got := received.Header.Get("Referer"); got != "http://example.test:8080/path" {
+		t.Errorf("Referer = %q", got)
+	}
+	if got := received.Header.Values("X-Token"); !reflect.DeepEqual(got, []string{"abc", "def"}) {
+		t.Errorf("X-Token = %q, want [abc def]", got)
+	}
+	if got := received.Header.Values("Host"); len(got) != 0 {
+		t.Errorf("Host sent in the header map as %q", got)
+	}
+}
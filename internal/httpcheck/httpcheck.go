@@ -0,0 +1,169 @@
+// Package httpcheck holds the flags and client setup shared by the HTTP checks
+package httpcheck
+
+import (
+	"crypto/tls"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"regexp"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/desylva/nagios/internal/nagios"
+	"github.com/desylva/nagios/internal/netcheck"
+)
+
+// PasswordEnv is the environment variable holding the basic auth password
+// when --auth is given a user name only, keeping it out of the process list
+const PasswordEnv = "CHECK_HTTP_PASSWORD"
+
+// Options are the command line flags common to every HTTP check
+type Options struct {
+	Timeout  nagios.DurationFlag
+	Insecure bool
+	Auth     string
+	Proxy    string
+	Family   *netcheck.Family
+}
+
+// AddFlags registers the common HTTP flags on the command line
+func AddFlags() *Options {
+	o := &Options{
+		Timeout: nagios.DurationFlag{Duration: 10 * time.Second},
+		Family:  netcheck.AddFlags(),
+	}
+	flag.Var(&o.Timeout, "t", "request timeout, as a duration or in seconds")
+	flag.Var(&o.Timeout, "timeout", "request timeout, as a duration or in seconds")
+	flag.BoolVar(&o.Insecure, "k", false, "skip TLS certificate verification")
+	flag.BoolVar(&o.Insecure, "insecure", false, "skip TLS certificate verification")
+	flag.StringVar(&o.Auth, "auth", "", "basic auth credentials as user:pass, or user with the password in $"+PasswordEnv)
+	flag.StringVar(&o.Proxy, "proxy", "", "proxy url as http://[user:pass@]host:port (default: $HTTP_PROXY and $HTTPS_PROXY)")
+	return o
+}
+
+// NewRequest builds a request for the check, applying the credentials
+func (o *Options) NewRequest(method, targetURL string, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequest(method, targetURL, body)
+	if err != nil {
+		return nil, err
+	}
+	if o.Auth != "" {
+		user, pass := o.Auth, os.Getenv(PasswordEnv)
+		if i := strings.Index(o.Auth, ":"); i >= 0 {
+			user, pass = o.Auth[:i], o.Auth[i+1:]
+		}
+		req.SetBasicAuth(user, pass)
+	}
+	return req, nil
+}
+
+// Client returns a client configured from the options.
+// An invalid --proxy exits UNKNOWN.
+func (o *Options) Client() *http.Client {
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.TLSClientConfig = &tls.Config{InsecureSkipVerify: o.Insecure}
+	if o.Proxy != "" {
+		proxyURL, err := url.Parse(o.Proxy)
+		if err != nil || proxyURL.Host == "" {
+			nagios.Usage("Invalid --proxy %q", o.Proxy)
+		}
+		transport.Proxy = http.ProxyURL(proxyURL)
+	}
+	if o.Family.IPv4 || o.Family.IPv6 {
+		transport.DialContext = o.Family.DialContext
+	}
+	return &http.Client{
+		Transport: transport,
+		Timeout:   o.Timeout.Duration,
+	}
+}
+
+// Failure converts an error returned by the client into a CRITICAL result.
+// Failures reaching the proxy are reported apart from those of the target.
+func (o *Options) Failure(err error, targetURL string) nagios.Result {
+	var noAddr *netcheck.NoAddressError
+	if errors.As(err, &noAddr) {
+		return nagios.Result{Status: nagios.Critical, Summary: noAddr.Error()}
+	}
+	var opErr *net.OpError
+	if errors.As(err, &opErr) && opErr.Op == "proxyconnect" {
+		return nagios.Result{
+			Status:  nagios.Critical,
+			Summary: fmt.Sprintf("proxy %v failed requesting %v: %v", o.proxyName(), targetURL, opErr.Err),
+		}
+	}
+	if err, ok := err.(net.Error); ok && err.Timeout() {
+		return nagios.Result{
+			Status:  nagios.Critical,
+			Summary: fmt.Sprintf("timed out after %v requesting %v", o.Timeout.Duration, targetURL),
+		}
+	}
+	return nagios.Result{Status: nagios.Critical, Summary: err.Error()}
+}
+
+// Transient reports whether a request error is plausibly temporary and worth
+// retrying: timeouts, refused, reset or aborted connections and early EOFs.
+// Certificate verification failures and the like are not.
+func Transient(err error) bool {
+	var netErr net.Error
+	if errors.As(err, &netErr) && netErr.Timeout() {
+		return true
+	}
+	return errors.Is(err, syscall.ECONNREFUSED) ||
+		errors.Is(err, syscall.ECONNRESET) ||
+		errors.Is(err, syscall.ECONNABORTED) ||
+		errors.Is(err, io.EOF) ||
+		errors.Is(err, io.ErrUnexpectedEOF)
+}
+
+// proxyName describes the proxy in use without its credentials
+func (o *Options) proxyName() string {
+	if o.Proxy == "" {
+		return "from environment"
+	}
+	proxyURL, err := url.Parse(o.Proxy)
+	if err != nil {
+		return o.Proxy
+	}
+	return proxyURL.Redacted()
+}
+
+var realmPattern = regexp.MustCompile(`realm="([^"]*)"`)
+
+// Unauthorized reports a 401 response as CRITICAL, echoing the realm from
+// the WWW-Authenticate header. This is the expected outcome when a protected
+// url is checked without --auth, or with the wrong credentials.
+// A 407 from the proxy is reported the same way.
+func Unauthorized(resp *http.Response) (nagios.Result, bool) {
+	var summary, challenge string
+	switch resp.StatusCode {
+	case http.StatusUnauthorized:
+		summary = fmt.Sprintf("%v requires authentication", resp.Request.URL)
+		challenge = resp.Header.Get("WWW-Authenticate")
+	case http.StatusProxyAuthRequired:
+		summary = fmt.Sprintf("proxy requires authentication requesting %v", resp.Request.URL)
+		challenge = resp.Header.Get("Proxy-Authenticate")
+	default:
+		return nagios.Result{}, false
+	}
+	if m := realmPattern.FindStringSubmatch(challenge); m != nil {
+		summary += fmt.Sprintf(" (realm %q)", m[1])
+	}
+	return nagios.Result{Status: nagios.Critical, Summary: summary}, true
+}
+
+// Note returns the remark appended to OK and WARNING summaries when
+// certificate verification is disabled
+func (o *Options) Note() string {
+	if o.Insecure {
+		return " (certificate verification skipped)"
+	}
+	return ""
+}
@@ -0,0 +1,34 @@
+package httpcheck
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/desylva/nagios/internal/nagios"
+	"github.com/desylva/nagios/internal/netcheck"
+)
+
+func TestClientInsecure(t *testing.T) {
+	srv := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	defer srv.Close()
+
+	for _, insecure := range []bool{false, true} {
+		o := &Options{
+			Timeout:  nagios.DurationFlag{Duration: 5 * time.Second},
+			Insecure: insecure,
+			Family:   &netcheck.Family{},
+		}
+		resp, err := o.Client().Get(srv.URL)
+		if err == nil {
+			resp.Body.Close()
+		}
+		if insecure && err != nil {
+			t.Errorf("with Insecure: %v", err)
+		}
+		if !insecure && err == nil {
+			t.Errorf("without Insecure: self-signed certificate accepted")
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package httpcheck
+
+import (
+	"crypto/tls"
+	"net/http"
+	"net/http/httptrace"
+	"sync"
+	"time"
+
+	"github.com/desylva/nagios/internal/nagios"
+)
+
+// Timing adds up the phases of a request and its redirects, as traced by
+// httptrace. Phases skipped on a reused connection count as zero.
+type Timing struct {
+	mu      sync.Mutex
+	DNS     time.Duration
+	Connect time.Duration
+	TLS     time.Duration
+	// TTFB is the wait from the request written to the first response byte
+	TTFB time.Duration
+
+	dnsStart, connectStart, tlsStart, wrote time.Time
+}
+
+// Trace returns req with a context recording its timing into t. The
+// client passes the context on to the redirects.
+func (t *Timing) Trace(req *http.Request) *http.Request {
+	trace := &httptrace.ClientTrace{
+		DNSStart: func(httptrace.DNSStartInfo) {
+			t.start(&t.dnsStart)
+		},
+		DNSDone: func(httptrace.DNSDoneInfo) {
+			t.done(&t.dnsStart, &t.DNS)
+		},
+		ConnectStart: func(_, _ string) {
+			t.start(&t.connectStart)
+		},
+		ConnectDone: func(_, _ string, err error) {
+			if err == nil {
+				t.done(&t.connectStart, &t.Connect)
+			}
+		},
+		TLSHandshakeStart: func() {
+			t.start(&t.tlsStart)
+		},
+		TLSHandshakeDone: func(tls.ConnectionState, error) {
+			t.done(&t.tlsStart, &t.TLS)
+		},
+		WroteRequest: func(httptrace.WroteRequestInfo) {
+			t.start(&t.wrote)
+		},
+		GotFirstResponseByte: func() {
+			t.done(&t.wrote, &t.TTFB)
+		},
+	}
+	return req.WithContext(httptrace.WithClientTrace(req.Context(), trace))
+}
+
+// Reset clears the phases before retrying the request
+func (t *Timing) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.DNS, t.Connect, t.TLS, t.TTFB = 0, 0, 0, 0
+	t.dnsStart, t.connectStart, t.tlsStart, t.wrote = time.Time{}, time.Time{}, time.Time{}, time.Time{}
+}
+
+// start records the start of a phase, keeping the earliest of parallel
+// attempts such as dual-stack dials
+func (t *Timing) start(at *time.Time) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if at.IsZero() {
+		*at = time.Now()
+	}
+}
+
+// done adds the phase started at to total
+func (t *Timing) done(at *time.Time, total *time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !at.IsZero() {
+		*total += time.Since(*at)
+		*at = time.Time{}
+	}
+}
+
+// Perfdata returns the phases as dns, connect, tls and ttfb perfdata in
+// seconds, followed by total
+func (t *Timing) Perfdata(total time.Duration) []nagios.Perfdata {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	phases := []struct {
+		label    string
+		duration time.Duration
+	}{
+		{"dns", t.DNS},
+		{"connect", t.Connect},
+		{"tls", t.TLS},
+		{"ttfb", t.TTFB},
+		{"total", total},
+	}
+	perfdata := make([]nagios.Perfdata, len(phases))
+	for i, phase := range phases {
+		perfdata[i] = nagios.Perfdata{
+			Label: phase.label,
+			Value: phase.duration.Round(time.Millisecond).Seconds(),
+			UOM:   "s",
+			Min:   "0",
+		}
+	}
+	return perfdata
+}
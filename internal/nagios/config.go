@@ -0,0 +1,158 @@
+package nagios
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// DefaultConfigPaths are searched in order for a config file when --config
+// is not given. The first one found is used.
+var DefaultConfigPaths = []string{
+	"$HOME/.config/nagios/checks.conf",
+	"/etc/nagios/checks.conf",
+}
+
+// toolName returns the name of the running check, e.g. check_http_redirection
+func toolName() string {
+	return strings.TrimSuffix(filepath.Base(os.Args[0]), filepath.Ext(os.Args[0]))
+}
+
+// envName returns the environment variable supplying a flag of the running
+// check, e.g. CHECK_HTTP_REDIRECTION_TIMEOUT for --timeout
+func envName(flagName string) string {
+	name := toolName() + "_" + flagName
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(name))
+}
+
+// configValue is a flag value read from a config file
+type configValue struct {
+	name   string
+	value  string
+	line   int
+	global bool
+}
+
+// findConfig returns the config file to load, or "" when there is none
+func findConfig(path string) string {
+	if path != "" {
+		return path
+	}
+	for _, candidate := range DefaultConfigPaths {
+		candidate = os.ExpandEnv(candidate)
+		if _, err := os.Stat(candidate); err == nil {
+			return candidate
+		}
+	}
+	return ""
+}
+
+// readConfig reads the flag values for tool from a config file.
+//
+// The file holds one "name = value" (TOML style) or "name: value" (YAML
+// style) per line, where name is a flag name without dashes. Values may
+// be quoted, and "[a, b]" lists, or a "name:" followed by "- value" lines,
+// set repeatable flags several times. Lines starting with # are comments.
+// Values before any section apply to every check defining the flag; a
+// "[check_name]" header, or an unindented "check_name:" with indented
+// values below it, starts a section applying to that check only.
+func readConfig(path, tool string) ([]configValue, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	var values []configValue
+	section := ""
+	yamlSection := false
+	// A "name:" without value may also start a list of "- value" lines,
+	// in the section it appears in
+	list, listSection := "", ""
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		raw := scanner.Text()
+		line := strings.TrimSpace(raw)
+		if line == "" || strings.HasPrefix(line, "#") || line == "---" {
+			continue
+		}
+		indented := raw[0] == ' ' || raw[0] == '\t'
+		if line == "-" || strings.HasPrefix(line, "- ") {
+			if list == "" {
+				return nil, fmt.Errorf("%v:%v: list item without a 'name:' above it", path, n)
+			}
+			if yamlSection && section == list {
+				// The unindented "name:" starts a list, not a section
+				section, yamlSection = listSection, false
+			}
+			if listSection == "" || listSection == tool {
+				values = append(values, configValue{
+					name:   strings.TrimLeft(list, "-"),
+					value:  unquote(strings.TrimSpace(line[1:])),
+					line:   n,
+					global: listSection == "",
+				})
+			}
+			continue
+		}
+		list = ""
+		if yamlSection && !indented {
+			section, yamlSection = "", false
+		}
+		if strings.HasPrefix(line, "[") && strings.HasSuffix(line, "]") {
+			section = strings.TrimSpace(line[1 : len(line)-1])
+			continue
+		}
+
+		i := strings.IndexAny(line, "=:")
+		if i <= 0 {
+			return nil, fmt.Errorf("%v:%v: expects 'name = value' or 'name: value'", path, n)
+		}
+		name := strings.TrimSpace(line[:i])
+		value := strings.TrimSpace(line[i+1:])
+		if value == "" && line[i] == ':' {
+			list, listSection = name, section
+			if !indented {
+				section, yamlSection = name, true
+			}
+			continue
+		}
+		if section != "" && section != tool {
+			continue
+		}
+
+		items := []string{value}
+		if strings.HasPrefix(value, "[") && strings.HasSuffix(value, "]") {
+			items = strings.Split(value[1:len(value)-1], ",")
+		}
+		for _, item := range items {
+			values = append(values, configValue{
+				name:   strings.TrimLeft(name, "-"),
+				value:  unquote(strings.TrimSpace(item)),
+				line:   n,
+				global: section == "",
+			})
+		}
+	}
+	return values, scanner.Err()
+}
+
+// unquote strips the quotes around a config value, or a trailing comment
+// from an unquoted one
+func unquote(s string) string {
+	if len(s) >= 2 && s[0] == '"' {
+		if unquoted, err := strconv.Unquote(s); err == nil {
+			return unquoted
+		}
+	}
+	if len(s) >= 2 && s[0] == '\'' && s[len(s)-1] == '\'' {
+		return s[1 : len(s)-1]
+	}
+	if i := strings.Index(s, " #"); i >= 0 {
+		return strings.TrimSpace(s[:i])
+	}
+	return s
+}
@@ -0,0 +1,53 @@
+package nagios
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestReadConfigLists(t *testing.T) {
+	tests := []struct {
+		name   string
+		config string
+		want   []string
+	}{
+		{"inline", "cookie = [a=1, b=2]\n", []string{"cookie=a=1", "cookie=b=2"}},
+		{"indented block", "cookie:\n  - a=1\n  - 'b=2'\ntimeout: 5\n", []string{"cookie=a=1", "cookie=b=2", "timeout=5"}},
+		{"unindented block", "cookie:\n- a=1\n- b=2\n", []string{"cookie=a=1", "cookie=b=2"}},
+		{"yaml section", "check_test:\n  cookie:\n    - a=1\n  timeout: 5\ncheck_other:\n  cookie:\n    - b=2\n", []string{"cookie=a=1", "timeout=5"}},
+		{"toml section", "[check_test]\ncookie:\n  - a=1\n[check_other]\ncookie:\n  - b=2\n", []string{"cookie=a=1"}},
+		{"section", "check_test:\n  timeout: 5\n", []string{"timeout=5"}},
+	}
+	for _, test := range tests {
+		path := filepath.Join(t.TempDir(), "checks.conf")
+		if err := os.WriteFile(path, []byte(test.config), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		values, err := readConfig(path, "check_test")
+		if err != nil {
+			t.Errorf("%v: %v", test.name, err)
+			continue
+		}
+		var got []string
+		for _, v := range values {
+			got = append(got, v.name+"="+v.value)
+		}
+		if !reflect.DeepEqual(got, test.want) {
+			t.Errorf("%v: got %q, want %q", test.name, got, test.want)
+		}
+	}
+}
+
+func TestReadConfigStrayItem(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checks.conf")
+	if err := os.WriteFile(path, []byte("timeout: 5\n- a=1\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	_, err := readConfig(path, "check_test")
+	if err == nil || !strings.Contains(err.Error(), ":2:") {
+		t.Errorf("got error %v, want one naming line 2", err)
+	}
+}
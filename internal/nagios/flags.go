@@ -0,0 +1,121 @@
+package nagios
+
+import (
+	"flag"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RangeFlag is a flag.Value holding an optional threshold range
+type RangeFlag struct {
+	Range *Range
+}
+
+func (f *RangeFlag) String() string {
+	return f.Range.String()
+}
+
+// Set parses s as a Nagios range spec
+func (f *RangeFlag) Set(s string) error {
+	r, err := ParseRange(s)
+	if err != nil {
+		return err
+	}
+	f.Range = r
+	return nil
+}
+
+// DurationFlag is a flag.Value accepting either a Go duration string
+// such as "1m30s" or a plain number of seconds
+type DurationFlag struct {
+	Duration time.Duration
+}
+
+func (f *DurationFlag) String() string {
+	return f.Duration.String()
+}
+
+// Set parses s as a duration or a number of seconds
+func (f *DurationFlag) Set(s string) error {
+	if secs, err := strconv.ParseFloat(s, 64); err == nil {
+		f.Duration = time.Duration(secs * float64(time.Second))
+		return nil
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	f.Duration = d
+	return nil
+}
+
+// ParseFlags parses the command line flags. Unlike flag.Parse, invalid
+// flags exit UNKNOWN rather than with flag's own exit code.
+//
+// Flags missing from the command line are then taken from the environment,
+// e.g. CHECK_HTTP_REDIRECTION_TIMEOUT for --timeout of check_http_redirection,
+// and then from the --config file or the first of DefaultConfigPaths found.
+// The precedence is command line flag > environment variable > config file
+// > built-in default.
+//
+// Every check also gets the --output, --graphite and --metric-prefix flags.
+func ParseFlags() {
+	addOutputFlag()
+	addGraphiteFlags()
+	configPath := flag.String("config", "", "file supplying defaults for the flags (default: first of "+strings.Join(DefaultConfigPaths, ", ")+")")
+	flag.CommandLine.Init(os.Args[0], flag.ContinueOnError)
+	if err := flag.CommandLine.Parse(os.Args[1:]); err != nil {
+		if err == flag.ErrHelp {
+			os.Exit(int(Unknown))
+		}
+		Usage("%v", err)
+	}
+
+	// Aliases such as -t and --timeout share their Value, so a flag is
+	// only defaulted when none of its aliases was given
+	given := make(map[flag.Value]bool)
+	flag.Visit(func(f *flag.Flag) {
+		given[f.Value] = true
+	})
+
+	var fromEnv []*flag.Flag
+	flag.VisitAll(func(f *flag.Flag) {
+		if _, ok := os.LookupEnv(envName(f.Name)); ok && !given[f.Value] {
+			fromEnv = append(fromEnv, f)
+		}
+	})
+	for _, f := range fromEnv {
+		name := envName(f.Name)
+		if err := f.Value.Set(os.Getenv(name)); err != nil {
+			Usage("invalid value for $%v: %v", name, err)
+		}
+		given[f.Value] = true
+	}
+
+	path := findConfig(*configPath)
+	if path == "" {
+		return
+	}
+	values, err := readConfig(path, toolName())
+	if err != nil {
+		Usage("cannot read config: %v", err)
+	}
+	for _, v := range values {
+		f := flag.Lookup(v.name)
+		if f == nil {
+			if v.global {
+				// Shared values only apply to the checks defining the flag
+				continue
+			}
+			Usage("%v:%v: unknown flag %q", path, v.line, v.name)
+		}
+		if given[f.Value] {
+			continue
+		}
+		if err := f.Value.Set(v.value); err != nil {
+			Usage("%v:%v: invalid value for %v: %v", path, v.line, v.name, err)
+		}
+	}
+}
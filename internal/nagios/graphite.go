@@ -0,0 +1,61 @@
+package nagios
+
+import (
+	"flag"
+	"fmt"
+	"net"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// graphite is the host:port Result.Exit pushes the perfdata to, "" for none
+var graphite string
+
+// metricPrefix is prepended to the perfdata labels to name the metrics
+var metricPrefix string
+
+// graphiteTimeout bounds the push so an unreachable Graphite cannot stall the check
+const graphiteTimeout = 2 * time.Second
+
+// addGraphiteFlags registers the --graphite and --metric-prefix flags
+// pushing the perfdata to a Graphite plaintext listener on exit
+func addGraphiteFlags() {
+	flag.StringVar(&graphite, "graphite", "", "Graphite plaintext listener as host:port the perfdata is also pushed to")
+	flag.StringVar(&metricPrefix, "metric-prefix", "", "prefix of the pushed metric names (default: nagios.<check name>)")
+}
+
+// metricUnsafe matches the characters not allowed in a metric name part
+var metricUnsafe = regexp.MustCompile(`[^A-Za-z0-9_-]+`)
+
+// push sends each perfdata as a "prefix.label value timestamp" line to
+// --graphite. It is best-effort: failures are reported on stderr only and
+// never change the status.
+func (r Result) push() {
+	if graphite == "" || len(r.Perfdata) == 0 {
+		return
+	}
+	prefix := metricPrefix
+	if prefix == "" {
+		prefix = "nagios." + toolName()
+	}
+	prefix = strings.TrimSuffix(prefix, ".")
+
+	var lines strings.Builder
+	now := time.Now().Unix()
+	for _, p := range r.Perfdata {
+		name := strings.Trim(metricUnsafe.ReplaceAllString(p.Label, "_"), "_")
+		fmt.Fprintf(&lines, "%v.%v %v %v\n", prefix, name, p.Value, now)
+	}
+
+	conn, err := net.DialTimeout("tcp", graphite, graphiteTimeout)
+	if err == nil {
+		conn.SetDeadline(time.Now().Add(graphiteTimeout))
+		_, err = conn.Write([]byte(lines.String()))
+		conn.Close()
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "cannot push metrics to graphite %v: %v\n", graphite, err)
+	}
+}
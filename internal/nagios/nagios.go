@@ -0,0 +1,94 @@
+// Package nagios holds the output contract shared by every check tool:
+// statuses, exit codes, perfdata and the single result line.
+package nagios
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+// Status is a Nagios plugin state. Its value is the process exit code.
+type Status int
+
+// Nagios plugin states, in the order of their exit codes
+const (
+	OK Status = iota
+	Warning
+	Critical
+	Unknown
+)
+
+func (s Status) String() string {
+	switch s {
+	case OK:
+		return "OK"
+	case Warning:
+		return "WARNING"
+	case Critical:
+		return "CRITICAL"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+// severity orders the statuses from best to worst
+var severity = map[Status]int{OK: 0, Warning: 1, Unknown: 2, Critical: 3}
+
+// Worst returns the most severe of the statuses, ranking CRITICAL above
+// UNKNOWN above WARNING above OK
+func Worst(statuses ...Status) Status {
+	worst := OK
+	for _, s := range statuses {
+		if severity[s] > severity[worst] {
+			worst = s
+		}
+	}
+	return worst
+}
+
+// Result is the outcome of a check. Details are optional extra lines
+// printed after the status line as the plugin long output.
+type Result struct {
+	Status   Status
+	Summary  string
+	Perfdata []Perfdata
+	Details  []string
+}
+
+// String formats the result as the plugin output line,
+// e.g. "OK: Returns url https://example.com/ | time=0.231s;;;0",
+// followed by the details one per line
+func (r Result) String() string {
+	line := r.Status.String() + ": " + r.Summary
+	if len(r.Perfdata) > 0 {
+		fields := make([]string, len(r.Perfdata))
+		for i, p := range r.Perfdata {
+			fields[i] = p.String()
+		}
+		line += " | " + strings.Join(fields, " ")
+	}
+	for _, detail := range r.Details {
+		line += "\n" + detail
+	}
+	return line
+}
+
+// Exit prints the result to stdout, as a line or as JSON with --output json,
+// and exits with the status code. With --graphite, the perfdata is pushed
+// first.
+func (r Result) Exit() {
+	r.push()
+	if output == "json" {
+		fmt.Println(r.JSON())
+	} else {
+		fmt.Println(r.String())
+	}
+	os.Exit(int(r.Status))
+}
+
+// Usage reports an invocation problem on stderr and exits UNKNOWN
+func Usage(format string, a ...interface{}) {
+	fmt.Fprintf(os.Stderr, "UNKNOWN: "+format+"\n", a...)
+	os.Exit(int(Unknown))
+}
@@ -0,0 +1,65 @@
+package nagios
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+)
+
+// output is the format Result.Exit prints in, "text" or "json"
+var output = "text"
+
+type outputFlag struct{}
+
+func (outputFlag) String() string {
+	return output
+}
+
+func (outputFlag) Set(s string) error {
+	if s != "text" && s != "json" {
+		return fmt.Errorf("unknown output format %q, expects text or json", s)
+	}
+	output = s
+	return nil
+}
+
+// addOutputFlag registers the --output flag selecting between the plain
+// plugin line (the default) and a JSON document
+func addOutputFlag() {
+	flag.Var(outputFlag{}, "output", "output format: text or json")
+}
+
+type jsonPerfdata struct {
+	Label string  `json:"label"`
+	Value float64 `json:"value"`
+	UOM   string  `json:"uom,omitempty"`
+	Warn  string  `json:"warn,omitempty"`
+	Crit  string  `json:"crit,omitempty"`
+	Min   string  `json:"min,omitempty"`
+	Max   string  `json:"max,omitempty"`
+}
+
+type jsonResult struct {
+	Status   string         `json:"status"`
+	Code     int            `json:"code"`
+	Summary  string         `json:"summary"`
+	Perfdata []jsonPerfdata `json:"perfdata"`
+	Details  []string       `json:"details,omitempty"`
+}
+
+// JSON formats the result as a JSON document, e.g.
+// {"status":"OK","code":0,"summary":"...","perfdata":[{"label":"time","value":0.23,"uom":"s"}]}
+func (r Result) JSON() string {
+	doc := jsonResult{
+		Status:   r.Status.String(),
+		Code:     int(r.Status),
+		Summary:  r.Summary,
+		Perfdata: make([]jsonPerfdata, len(r.Perfdata)),
+		Details:  r.Details,
+	}
+	for i, p := range r.Perfdata {
+		doc.Perfdata[i] = jsonPerfdata(p)
+	}
+	b, _ := json.Marshal(doc)
+	return string(b)
+}
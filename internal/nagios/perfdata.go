@@ -0,0 +1,33 @@
+package nagios
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Perfdata is a single performance data metric.
+// Warn, Crit, Min and Max are left out of the output when empty.
+type Perfdata struct {
+	Label string
+	Value float64
+	UOM   string
+	Warn  string
+	Crit  string
+	Min   string
+	Max   string
+}
+
+// String formats the metric as 'label'=value[UOM];[warn];[crit];[min];[max]
+func (p Perfdata) String() string {
+	label := p.Label
+	if strings.ContainsAny(label, " '=") {
+		label = "'" + strings.Replace(label, "'", "''", -1) + "'"
+	}
+	field := label + "=" + strconv.FormatFloat(p.Value, 'f', -1, 64) + p.UOM
+	tail := strings.Join([]string{p.Warn, p.Crit, p.Min, p.Max}, ";")
+	tail = strings.TrimRight(tail, ";")
+	if tail != "" {
+		field += ";" + tail
+	}
+	return field
+}
@@ -0,0 +1,91 @@
+package nagios
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Range is a threshold in the Nagios range syntax, e.g. "10", "10:",
+// "~:10", "10:20" or "@10:20". A value outside [Start, End] breaches the
+// range, or inside it when Inside is set by a leading "@".
+type Range struct {
+	Start  float64
+	End    float64
+	Inside bool
+	spec   string
+}
+
+// ParseRange parses a Nagios range spec
+func ParseRange(s string) (*Range, error) {
+	r := &Range{Start: 0, End: math.Inf(1), spec: s}
+	spec := s
+	if strings.HasPrefix(spec, "@") {
+		r.Inside = true
+		spec = spec[1:]
+	}
+	if spec == "" {
+		return nil, fmt.Errorf("invalid range %q", s)
+	}
+
+	end := spec
+	if i := strings.Index(spec, ":"); i >= 0 {
+		start := spec[:i]
+		end = spec[i+1:]
+		switch start {
+		case "~":
+			r.Start = math.Inf(-1)
+		case "":
+			return nil, fmt.Errorf("invalid range %q: missing start", s)
+		default:
+			v, err := strconv.ParseFloat(start, 64)
+			if err != nil {
+				return nil, fmt.Errorf("invalid range %q: %v", s, err)
+			}
+			r.Start = v
+		}
+	}
+	if end != "" {
+		v, err := strconv.ParseFloat(end, 64)
+		if err != nil {
+			return nil, fmt.Errorf("invalid range %q: %v", s, err)
+		}
+		r.End = v
+	}
+
+	if r.Start > r.End {
+		return nil, fmt.Errorf("invalid range %q: start is greater than end", s)
+	}
+	return r, nil
+}
+
+// Breached reports whether v triggers an alert for the range
+func (r *Range) Breached(v float64) bool {
+	inside := v >= r.Start && v <= r.End
+	if r.Inside {
+		return inside
+	}
+	return !inside
+}
+
+// String returns the spec the range was parsed from, as used in perfdata.
+// A nil range formats as the empty string.
+func (r *Range) String() string {
+	if r == nil {
+		return ""
+	}
+	return r.spec
+}
+
+// Compare returns the status of v against the optional warning and critical
+// ranges. Nil ranges never breach.
+func Compare(v float64, warn, crit *Range) Status {
+	if crit != nil && crit.Breached(v) {
+		return Critical
+	}
+	if warn != nil && warn.Breached(v) {
+		return Warning
+	}
+	return OK
+}
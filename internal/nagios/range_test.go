@@ -0,0 +1,48 @@
+package nagios
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParseRange(t *testing.T) {
+	tests := []struct {
+		spec     string
+		breached []float64
+		ok       []float64
+	}{
+		{"10", []float64{-1, 10.5, 11}, []float64{0, 5, 10}},
+		{"10:", []float64{-1, 9.9}, []float64{10, 11, math.Inf(1)}},
+		{"~:10", []float64{10.1, 11}, []float64{math.Inf(-1), -5, 10}},
+		{"10:20", []float64{9.9, 20.1}, []float64{10, 15, 20}},
+		{"@10:20", []float64{10, 15, 20}, []float64{9.9, 20.1}},
+	}
+	for _, test := range tests {
+		r, err := ParseRange(test.spec)
+		if err != nil {
+			t.Errorf("ParseRange(%q): %v", test.spec, err)
+			continue
+		}
+		if r.String() != test.spec {
+			t.Errorf("ParseRange(%q).String() = %q", test.spec, r.String())
+		}
+		for _, v := range test.breached {
+			if !r.Breached(v) {
+				t.Errorf("ParseRange(%q).Breached(%v) = false, want true", test.spec, v)
+			}
+		}
+		for _, v := range test.ok {
+			if r.Breached(v) {
+				t.Errorf("ParseRange(%q).Breached(%v) = true, want false", test.spec, v)
+			}
+		}
+	}
+}
+
+func TestParseRangeInvalid(t *testing.T) {
+	for _, spec := range []string{"10:5", "", "@", ":10", "x", "1:y"} {
+		if r, err := ParseRange(spec); err == nil {
+			t.Errorf("ParseRange(%q) = %+v, want an error", spec, r)
+		}
+	}
+}
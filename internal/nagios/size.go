@@ -0,0 +1,25 @@
+package nagios
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ParseSize parses a size in bytes with an optional binary K, M, G or T
+// suffix, e.g. "500M"
+func ParseSize(s string) (float64, error) {
+	number := s
+	multiplier := 1.0
+	if number != "" {
+		if i := strings.IndexByte("KMGT", number[len(number)-1]); i >= 0 {
+			multiplier = float64(uint64(1) << (10 * uint(i+1)))
+			number = number[:len(number)-1]
+		}
+	}
+	v, err := strconv.ParseFloat(number, 64)
+	if err != nil || v < 0 {
+		return 0, fmt.Errorf("invalid size %q, expects a size like 500M", s)
+	}
+	return v * multiplier, nil
+}
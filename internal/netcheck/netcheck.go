@@ -0,0 +1,113 @@
+// Package netcheck holds the dialing options shared by the connectivity checks
+package netcheck
+
+import (
+	"context"
+	"errors"
+	"flag"
+	"fmt"
+	"net"
+	"time"
+
+	"github.com/desylva/nagios/internal/nagios"
+)
+
+// Family holds the -4 and -6 flags forcing the address family to dial
+type Family struct {
+	IPv4 bool
+	IPv6 bool
+}
+
+// AddFlags registers the -4 and -6 flags on the command line
+func AddFlags() *Family {
+	f := &Family{}
+	flag.BoolVar(&f.IPv4, "4", false, "connect over IPv4 only")
+	flag.BoolVar(&f.IPv6, "6", false, "connect over IPv6 only")
+	return f
+}
+
+// NoAddressError is returned when the host has no address in the forced family
+type NoAddressError struct {
+	Host   string
+	Family string
+}
+
+func (e *NoAddressError) Error() string {
+	return fmt.Sprintf("no %v address for host %v", e.Family, e.Host)
+}
+
+// errBothFamilies is returned when dialing with both -4 and -6, which
+// Validate rejects beforehand
+var errBothFamilies = errors.New("expects only one of -4 and -6")
+
+// Validate exits UNKNOWN when both families are forced. Tools call it
+// right after nagios.ParseFlags, before dialing.
+func (f *Family) Validate() {
+	if f.IPv4 && f.IPv6 {
+		nagios.Usage("Expects only one of -4 and -6")
+	}
+}
+
+// network returns the network to resolve and dial
+func (f *Family) network() (string, error) {
+	switch {
+	case f.IPv4 && f.IPv6:
+		return "", errBothFamilies
+	case f.IPv4:
+		return "tcp4", nil
+	case f.IPv6:
+		return "tcp6", nil
+	}
+	return "tcp", nil
+}
+
+// DialContext dials address over the forced family, with the signature of
+// http.Transport.DialContext. The network requested by the caller is ignored.
+func (f *Family) DialContext(ctx context.Context, _, address string) (net.Conn, error) {
+	var d net.Dialer
+	network, err := f.network()
+	if err != nil {
+		return nil, err
+	}
+	if network == "tcp" {
+		return d.DialContext(ctx, network, address)
+	}
+
+	host, port, err := net.SplitHostPort(address)
+	if err != nil {
+		return nil, err
+	}
+	ipNetwork, name := "ip4", "IPv4"
+	if network == "tcp6" {
+		ipNetwork, name = "ip6", "IPv6"
+	}
+	ips, err := net.DefaultResolver.LookupIP(ctx, ipNetwork, host)
+	if err != nil || len(ips) == 0 {
+		if dnsErr, ok := err.(*net.DNSError); ok {
+			// Not found for one family may still mean the host does not exist
+			if !dnsErr.IsNotFound {
+				return nil, err
+			}
+			if _, anyErr := net.DefaultResolver.LookupIP(ctx, "ip", host); anyErr != nil {
+				return nil, anyErr
+			}
+		}
+		return nil, &NoAddressError{Host: host, Family: name}
+	}
+
+	for _, ip := range ips {
+		var conn net.Conn
+		conn, err = d.DialContext(ctx, network, net.JoinHostPort(ip.String(), port))
+		if err == nil {
+			return conn, nil
+		}
+	}
+	return nil, err
+}
+
+// Dial dials address over the forced family within timeout
+func (f *Family) Dial(address string, timeout time.Duration) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+	return f.DialContext(ctx, "tcp", address)
+}
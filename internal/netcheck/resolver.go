@@ -0,0 +1,37 @@
+package netcheck
+
+import (
+	"context"
+	"net"
+	"strings"
+)
+
+// Resolver returns a resolver querying server as host[:port], port 53 by
+// default, or the system resolver when server is empty
+func Resolver(server string) *net.Resolver {
+	if server == "" {
+		return net.DefaultResolver
+	}
+	address := server
+	if _, _, err := net.SplitHostPort(address); err != nil {
+		address = net.JoinHostPort(address, "53")
+	}
+	return &net.Resolver{
+		PreferGo: true,
+		Dial: func(ctx context.Context, network, _ string) (net.Conn, error) {
+			var d net.Dialer
+			return d.DialContext(ctx, network, address)
+		},
+	}
+}
+
+// FQDN returns name fully qualified with a trailing dot, so the resolver
+// does not try the search domains and answer for name.<search domain>.
+// Single labels like localhost are left to the hosts file, which ignores
+// them with a trailing dot.
+func FQDN(name string) string {
+	if !strings.Contains(name, ".") || strings.HasSuffix(name, ".") {
+		return name
+	}
+	return name + "."
+}
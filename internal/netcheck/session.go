@@ -0,0 +1,66 @@
+package netcheck
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/textproto"
+	"strings"
+	"time"
+
+	"github.com/desylva/nagios/internal/nagios"
+)
+
+// ReplyError is an error reply of a protocol without textproto replies,
+// like "NOAUTH Authentication required." in Redis. Failure reports it as
+// the server's reply.
+type ReplyError interface {
+	error
+	Reply() string
+}
+
+// Command sends a command of a text protocol like SMTP or FTP and reads its
+// reply, failing when the reply code does not match expectCode as with
+// textproto.ReadResponse
+func Command(text *textproto.Conn, expectCode int, format string, args ...interface{}) (int, string, error) {
+	id, err := text.Cmd(format, args...)
+	if err != nil {
+		return 0, "", err
+	}
+	text.StartResponse(id)
+	defer text.EndResponse(id)
+	return text.ReadResponse(expectCode)
+}
+
+// Failure converts an error at a stage of a session with address into a
+// CRITICAL result
+func Failure(err error, stage string, timeout time.Duration, address string) nagios.Result {
+	var summary string
+	switch e := err.(type) {
+	case *textproto.Error:
+		summary = fmt.Sprintf("%v replies %v %v to %v", address, e.Code, FirstLine(e.Msg), stage)
+	case ReplyError:
+		summary = fmt.Sprintf("%v replies %v to %v", address, e.Reply(), stage)
+	case net.Error:
+		if e.Timeout() {
+			summary = fmt.Sprintf("timed out after %v during %v with %v", timeout, stage, address)
+		} else {
+			summary = err.Error()
+		}
+	default:
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			summary = fmt.Sprintf("%v closed the connection during %v", address, stage)
+		} else {
+			summary = fmt.Sprintf("%v during %v with %v", err, stage, address)
+		}
+	}
+	return nagios.Result{Status: nagios.Critical, Summary: summary}
+}
+
+// FirstLine returns the first line of a possibly multi-line reply
+func FirstLine(s string) string {
+	if i := strings.Index(s, "\n"); i >= 0 {
+		return s[:i]
+	}
+	return s
+}
@@ -0,0 +1,149 @@
+// Package sqlcheck holds the flags and query handling shared by the database checks
+package sqlcheck
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"flag"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/desylva/nagios/internal/nagios"
+)
+
+// valueError describes a query result that is not a single numeric value,
+// like "no rows"
+type valueError string
+
+func (e valueError) Error() string {
+	return string(e)
+}
+
+// Options are the command line flags common to every database check
+type Options struct {
+	Query   string
+	Warn    nagios.RangeFlag
+	Crit    nagios.RangeFlag
+	Timeout nagios.DurationFlag
+}
+
+// AddFlags registers the common database flags on the command line, with
+// the query run when --query is not given. An empty defaultQuery only
+// checks the connection.
+func AddFlags(defaultQuery string) *Options {
+	o := &Options{Timeout: nagios.DurationFlag{Duration: 10 * time.Second}}
+	flag.StringVar(&o.Query, "query", defaultQuery, "query returning a single numeric value checked against -w and -c")
+	flag.Var(&o.Warn, "w", "warning threshold on the value returned by --query")
+	flag.Var(&o.Crit, "c", "critical threshold on the value returned by --query")
+	flag.Var(&o.Timeout, "t", "connect and query timeout, as a duration or in seconds")
+	flag.Var(&o.Timeout, "timeout", "connect and query timeout, as a duration or in seconds")
+	return o
+}
+
+// Context returns a context expiring after --timeout
+func (o *Options) Context() (context.Context, context.CancelFunc) {
+	return context.WithTimeout(context.Background(), o.Timeout.Duration)
+}
+
+// Connect checks the database accepts connections, returning the error
+// for Failure to report
+func (o *Options) Connect(ctx context.Context, db *sql.DB) error {
+	return db.PingContext(ctx)
+}
+
+// Check connects to the database named server and runs --query, returning
+// its value against the thresholds. Without a query, the connect time is
+// reported instead.
+func (o *Options) Check(db *sql.DB, server string) nagios.Result {
+	ctx, cancel := o.Context()
+	defer cancel()
+
+	start := time.Now()
+	if err := o.Connect(ctx, db); err != nil {
+		return o.Failure(err, "connecting to "+server)
+	}
+	if o.Query == "" {
+		elapsed := time.Since(start).Round(time.Millisecond).Seconds()
+		return nagios.Result{
+			Status:   nagios.OK,
+			Summary:  fmt.Sprintf("Connected to %v in %vs", server, elapsed),
+			Perfdata: []nagios.Perfdata{{Label: "time", Value: elapsed, UOM: "s", Min: "0"}},
+		}
+	}
+
+	start = time.Now()
+	value, err := queryValue(ctx, db, o.Query)
+	elapsed := time.Since(start).Round(time.Millisecond).Seconds()
+	if _, ok := err.(valueError); ok {
+		return nagios.Result{Status: nagios.Unknown, Summary: fmt.Sprintf("Query on %v returned %v", server, err)}
+	}
+	if err != nil {
+		return o.Failure(err, "querying "+server)
+	}
+
+	return nagios.Result{
+		Status:  nagios.Compare(value, o.Warn.Range, o.Crit.Range),
+		Summary: fmt.Sprintf("Query on %v returned %v in %vs", server, strconv.FormatFloat(value, 'f', -1, 64), elapsed),
+		Perfdata: []nagios.Perfdata{{
+			Label: "value",
+			Value: value,
+			Warn:  o.Warn.String(),
+			Crit:  o.Crit.String(),
+		}, {
+			Label: "time",
+			Value: elapsed,
+			UOM:   "s",
+			Min:   "0",
+		}},
+	}
+}
+
+// queryValue runs query, which must return a single numeric column, and
+// returns the value of its first row
+func queryValue(ctx context.Context, db *sql.DB, query string) (float64, error) {
+	rows, err := db.QueryContext(ctx, query)
+	if err != nil {
+		return 0, err
+	}
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return 0, err
+	}
+	if len(columns) != 1 {
+		return 0, valueError(fmt.Sprintf("%v columns, expects a single one", len(columns)))
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return 0, err
+		}
+		return 0, valueError("no rows")
+	}
+	var raw sql.NullString
+	if err := rows.Scan(&raw); err != nil {
+		return 0, err
+	}
+	if !raw.Valid {
+		return 0, valueError("NULL")
+	}
+	value, err := strconv.ParseFloat(strings.TrimSpace(raw.String), 64)
+	if err != nil {
+		return 0, valueError(fmt.Sprintf("non-numeric value %q", raw.String))
+	}
+	return value, nil
+}
+
+// Failure converts a connection or query error into a CRITICAL result
+func (o *Options) Failure(err error, stage string) nagios.Result {
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nagios.Result{
+			Status:  nagios.Critical,
+			Summary: fmt.Sprintf("timed out after %v %v", o.Timeout.Duration, stage),
+		}
+	}
+	return nagios.Result{Status: nagios.Critical, Summary: fmt.Sprintf("%v while %v", err, stage)}
+}
@@ -0,0 +1,9 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package sysinfo
+
+// Device returns the device of the filesystem holding path
+func Device(path string) (uint64, error) {
+	return 0, ErrUnsupported
+}
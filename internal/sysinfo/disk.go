@@ -0,0 +1,23 @@
+package sysinfo
+
+// Disk is the space of a filesystem, in bytes. Free is the space available
+// to unprivileged users, while Used also counts the blocks reserved for
+// root as free, as df does.
+type Disk struct {
+	Total uint64
+	Free  uint64
+	Used  uint64
+}
+
+// newDisk returns the space of a filesystem from its block counts. avail
+// may be negative on the BSDs once the reserved blocks are in use.
+func newDisk(blockSize, blocks, free uint64, avail int64) Disk {
+	if avail < 0 {
+		avail = 0
+	}
+	return Disk{
+		Total: blocks * blockSize,
+		Free:  uint64(avail) * blockSize,
+		Used:  (blocks - free) * blockSize,
+	}
+}
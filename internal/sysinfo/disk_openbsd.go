@@ -0,0 +1,12 @@
+package sysinfo
+
+import "golang.org/x/sys/unix"
+
+// ReadDisk returns the space of the filesystem holding path
+func ReadDisk(path string) (Disk, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return Disk{}, err
+	}
+	return newDisk(uint64(st.F_bsize), st.F_blocks, st.F_bfree, st.F_bavail), nil
+}
@@ -0,0 +1,9 @@
+//go:build !aix && !darwin && !dragonfly && !freebsd && !linux && !netbsd && !openbsd && !solaris
+// +build !aix,!darwin,!dragonfly,!freebsd,!linux,!netbsd,!openbsd,!solaris
+
+package sysinfo
+
+// ReadDisk returns the space of the filesystem holding path
+func ReadDisk(path string) (Disk, error) {
+	return Disk{}, ErrUnsupported
+}
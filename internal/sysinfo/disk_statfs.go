@@ -0,0 +1,15 @@
+//go:build aix || darwin || dragonfly || freebsd || linux
+// +build aix darwin dragonfly freebsd linux
+
+package sysinfo
+
+import "golang.org/x/sys/unix"
+
+// ReadDisk returns the space of the filesystem holding path
+func ReadDisk(path string) (Disk, error) {
+	var st unix.Statfs_t
+	if err := unix.Statfs(path, &st); err != nil {
+		return Disk{}, err
+	}
+	return newDisk(uint64(st.Bsize), uint64(st.Blocks), uint64(st.Bfree), int64(st.Bavail)), nil
+}
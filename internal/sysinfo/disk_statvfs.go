@@ -0,0 +1,16 @@
+//go:build netbsd || solaris
+// +build netbsd solaris
+
+package sysinfo
+
+import "golang.org/x/sys/unix"
+
+// ReadDisk returns the space of the filesystem holding path. Unlike statfs,
+// statvfs counts the blocks in fragments of Frsize.
+func ReadDisk(path string) (Disk, error) {
+	var st unix.Statvfs_t
+	if err := unix.Statvfs(path, &st); err != nil {
+		return Disk{}, err
+	}
+	return newDisk(st.Frsize, st.Blocks, st.Bfree, int64(st.Bavail)), nil
+}
@@ -0,0 +1,30 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+// +build darwin dragonfly freebsd netbsd openbsd
+
+package sysinfo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// LoadAverage returns the 1, 5 and 15 minute load averages
+func LoadAverage() ([3]float64, error) {
+	var load [3]float64
+	// Prints "{ 1.23 1.10 0.98 }"
+	value, err := sysctl("vm.loadavg")
+	if err != nil {
+		return load, err
+	}
+	fields := strings.Fields(strings.Trim(value, "{}"))
+	if len(fields) < 3 {
+		return load, fmt.Errorf("unexpected vm.loadavg value %q", value)
+	}
+	for i := range load {
+		if load[i], err = strconv.ParseFloat(fields[i], 64); err != nil {
+			return load, fmt.Errorf("unexpected vm.loadavg value %q", value)
+		}
+	}
+	return load, nil
+}
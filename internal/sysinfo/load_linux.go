@@ -0,0 +1,27 @@
+package sysinfo
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// LoadAverage returns the 1, 5 and 15 minute load averages
+func LoadAverage() ([3]float64, error) {
+	var load [3]float64
+	b, err := os.ReadFile("/proc/loadavg")
+	if err != nil {
+		return load, err
+	}
+	fields := strings.Fields(string(b))
+	if len(fields) < 3 {
+		return load, fmt.Errorf("unexpected /proc/loadavg content %q", b)
+	}
+	for i := range load {
+		if load[i], err = strconv.ParseFloat(fields[i], 64); err != nil {
+			return load, fmt.Errorf("unexpected /proc/loadavg content %q", b)
+		}
+	}
+	return load, nil
+}
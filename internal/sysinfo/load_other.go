@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !dragonfly && !freebsd && !netbsd && !openbsd
+// +build !linux,!darwin,!dragonfly,!freebsd,!netbsd,!openbsd
+
+package sysinfo
+
+// LoadAverage returns the 1, 5 and 15 minute load averages
+func LoadAverage() ([3]float64, error) {
+	return [3]float64{}, ErrUnsupported
+}
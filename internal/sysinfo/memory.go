@@ -0,0 +1,11 @@
+package sysinfo
+
+// Memory is a snapshot of the memory and swap usage, in bytes.
+// Available is the memory usable without swapping, counting reclaimable
+// caches, rather than the strictly free memory.
+type Memory struct {
+	Total     uint64
+	Available uint64
+	SwapTotal uint64
+	SwapFree  uint64
+}
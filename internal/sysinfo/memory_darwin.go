@@ -0,0 +1,53 @@
+package sysinfo
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// ReadMemory returns the memory usage from sysctl
+func ReadMemory() (Memory, error) {
+	var mem Memory
+	var err error
+	if mem.Total, err = sysctlUint("hw.memsize"); err != nil {
+		return mem, err
+	}
+	pageSize, err := sysctlUint("hw.pagesize")
+	if err != nil {
+		return mem, err
+	}
+	var pages uint64
+	for _, name := range []string{"vm.page_free_count", "vm.page_speculative_count", "vm.page_purgeable_count"} {
+		count, err := sysctlUint(name)
+		if err != nil {
+			return mem, err
+		}
+		pages += count
+	}
+	mem.Available = pages * pageSize
+
+	// Prints "total = 2048.00M  used = 1024.00M  free = 1024.00M  (encrypted)"
+	swap, err := sysctl("vm.swapusage")
+	if err != nil {
+		return mem, err
+	}
+	fields := strings.Fields(swap)
+	for i := 0; i+2 < len(fields); i++ {
+		if fields[i+1] != "=" {
+			continue
+		}
+		size := strings.TrimSuffix(fields[i+2], "M")
+		mb, err := strconv.ParseFloat(size, 64)
+		if err != nil {
+			return mem, fmt.Errorf("unexpected vm.swapusage value %q", swap)
+		}
+		switch fields[i] {
+		case "total":
+			mem.SwapTotal = uint64(mb * (1 << 20))
+		case "free":
+			mem.SwapFree = uint64(mb * (1 << 20))
+		}
+	}
+	return mem, nil
+}
@@ -0,0 +1,31 @@
+package sysinfo
+
+// ReadMemory returns the memory usage from sysctl
+func ReadMemory() (Memory, error) {
+	var mem Memory
+	var err error
+	if mem.Total, err = sysctlUint("hw.physmem"); err != nil {
+		return mem, err
+	}
+	pageSize, err := sysctlUint("hw.pagesize")
+	if err != nil {
+		return mem, err
+	}
+	var pages uint64
+	for _, name := range []string{"vm.stats.vm.v_free_count", "vm.stats.vm.v_inactive_count"} {
+		count, err := sysctlUint(name)
+		if err != nil {
+			return mem, err
+		}
+		pages += count
+	}
+	mem.Available = pages * pageSize
+
+	// The swap in use is only exposed through swapinfo, so swap is
+	// reported as entirely free
+	if mem.SwapTotal, err = sysctlUint("vm.swap_total"); err != nil {
+		return mem, err
+	}
+	mem.SwapFree = mem.SwapTotal
+	return mem, nil
+}
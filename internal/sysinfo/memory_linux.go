@@ -0,0 +1,55 @@
+package sysinfo
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// ReadMemory returns the memory usage from /proc/meminfo
+func ReadMemory() (Memory, error) {
+	var mem Memory
+	f, err := os.Open("/proc/meminfo")
+	if err != nil {
+		return mem, err
+	}
+	defer f.Close()
+
+	// Lines look like "MemAvailable:    8042812 kB"
+	values := make(map[string]uint64)
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) < 2 {
+			continue
+		}
+		v, err := strconv.ParseUint(fields[1], 10, 64)
+		if err != nil {
+			continue
+		}
+		if len(fields) > 2 && fields[2] == "kB" {
+			v *= 1024
+		}
+		values[strings.TrimSuffix(fields[0], ":")] = v
+	}
+	if err := scanner.Err(); err != nil {
+		return mem, err
+	}
+
+	total, ok := values["MemTotal"]
+	if !ok {
+		return mem, fmt.Errorf("no MemTotal in /proc/meminfo")
+	}
+	mem.Total = total
+	if available, ok := values["MemAvailable"]; ok {
+		mem.Available = available
+	} else {
+		// Kernels before 3.14 lack MemAvailable
+		mem.Available = values["MemFree"] + values["Buffers"] + values["Cached"]
+	}
+	mem.SwapTotal = values["SwapTotal"]
+	mem.SwapFree = values["SwapFree"]
+	return mem, nil
+}
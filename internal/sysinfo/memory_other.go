@@ -0,0 +1,9 @@
+//go:build !linux && !darwin && !freebsd
+// +build !linux,!darwin,!freebsd
+
+package sysinfo
+
+// ReadMemory returns the memory usage
+func ReadMemory() (Memory, error) {
+	return Memory{}, ErrUnsupported
+}
@@ -0,0 +1,28 @@
+//go:build darwin || dragonfly || freebsd || netbsd || openbsd
+// +build darwin dragonfly freebsd netbsd openbsd
+
+package sysinfo
+
+import (
+	"os/exec"
+	"strconv"
+	"strings"
+)
+
+// sysctl returns the value of a kernel variable
+func sysctl(name string) (string, error) {
+	out, err := exec.Command("sysctl", "-n", name).Output()
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(string(out)), nil
+}
+
+// sysctlUint returns the value of a numeric kernel variable
+func sysctlUint(name string) (uint64, error) {
+	value, err := sysctl(name)
+	if err != nil {
+		return 0, err
+	}
+	return strconv.ParseUint(value, 10, 64)
+}
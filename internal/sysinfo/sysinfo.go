@@ -0,0 +1,11 @@
+// Package sysinfo reads system metrics for the local host checks,
+// hiding how each platform exposes them
+package sysinfo
+
+import (
+	"errors"
+	"runtime"
+)
+
+// ErrUnsupported is returned when a metric cannot be read on this platform
+var ErrUnsupported = errors.New("not supported on " + runtime.GOOS)